@@ -3,8 +3,9 @@ package ast
 import (
 	"fmt"
 
+	"github.com/dexon-foundation/decimal"
+
 	"github.com/dexon-foundation/dexon/core/vm/sqlvm/errors"
-	"github.com/shopspring/decimal"
 )
 
 // ---------------------------------------------------------------------------
@@ -19,6 +20,7 @@ type Node interface {
 	GetLength() uint32
 	SetLength(uint32)
 	GetChildren() []Node
+	Op() Op
 }
 
 // NodeBase is a base struct embedded by structs implementing Node interface.
@@ -108,14 +110,22 @@ func (n *TaggedExprNodeBase) SetType(t DataType) {
 // IdentifierNode references table, column, or function.
 type IdentifierNode struct {
 	TaggedExprNodeBase
-	Name []byte
+	// Qualifier disambiguates which table a column name refers to, e.g. the
+	// "a" in "a.id" -- needed once JoinNode lets a statement bring two table
+	// references into scope at once and their columns can collide. Nil means
+	// unqualified, the only form a non-JOIN statement ever needs.
+	Qualifier *IdentifierNode
+	Name      []byte
 }
 
 var _ ExprNode = (*IdentifierNode)(nil)
 
 // GetChildren returns a list of child nodes used for traversing.
 func (n *IdentifierNode) GetChildren() []Node {
-	return nil
+	if n.Qualifier == nil {
+		return nil
+	}
+	return []Node{n.Qualifier}
 }
 
 // IsConstant returns whether a node is a constant.
@@ -516,6 +526,87 @@ func (n *BoolTypeNode) GetType() (DataType, errors.ErrorCode, string) {
 		errors.ErrorCodeNil, ""
 }
 
+// DateTypeNode represents the SQL DATE type, stored as a day offset from the
+// epoch.
+type DateTypeNode struct {
+	NodeBase
+}
+
+var _ TypeNode = (*DateTypeNode)(nil)
+
+// GetChildren returns a list of child nodes used for traversing.
+func (n *DateTypeNode) GetChildren() []Node {
+	return nil
+}
+
+// GetType returns the type represented by the node.
+func (n *DateTypeNode) GetType() (DataType, errors.ErrorCode, string) {
+	return ComposeDataType(DataTypeMajorDate, DataTypeMinorDontCare),
+		errors.ErrorCodeNil, ""
+}
+
+// TimeTypeNode represents the SQL TIME type.
+type TimeTypeNode struct {
+	NodeBase
+	WithTimeZone bool
+	Precision    TimePrecision
+}
+
+var _ TypeNode = (*TimeTypeNode)(nil)
+
+// GetChildren returns a list of child nodes used for traversing.
+func (n *TimeTypeNode) GetChildren() []Node {
+	return nil
+}
+
+// GetType returns the type represented by the node.
+func (n *TimeTypeNode) GetType() (DataType, errors.ErrorCode, string) {
+	minor := composeTemporalMinor(n.WithTimeZone, n.Precision)
+	return ComposeDataType(DataTypeMajorTime, minor), errors.ErrorCodeNil, ""
+}
+
+// TimestampTypeNode represents the SQL TIMESTAMP type.
+type TimestampTypeNode struct {
+	NodeBase
+	WithTimeZone bool
+	Precision    TimePrecision
+}
+
+var _ TypeNode = (*TimestampTypeNode)(nil)
+
+// GetChildren returns a list of child nodes used for traversing.
+func (n *TimestampTypeNode) GetChildren() []Node {
+	return nil
+}
+
+// GetType returns the type represented by the node.
+func (n *TimestampTypeNode) GetType() (DataType, errors.ErrorCode, string) {
+	minor := composeTemporalMinor(n.WithTimeZone, n.Precision)
+	return ComposeDataType(DataTypeMajorTimestamp, minor),
+		errors.ErrorCodeNil, ""
+}
+
+// IntervalTypeNode represents the SQL INTERVAL type, holding the signed
+// difference between two TIMESTAMP values in its precision unit.
+type IntervalTypeNode struct {
+	NodeBase
+	Precision TimePrecision
+}
+
+var _ TypeNode = (*IntervalTypeNode)(nil)
+
+// GetChildren returns a list of child nodes used for traversing.
+func (n *IntervalTypeNode) GetChildren() []Node {
+	return nil
+}
+
+// GetType returns the type represented by the node.
+func (n *IntervalTypeNode) GetType() (DataType, errors.ErrorCode, string) {
+	minor := composeTemporalMinor(false, n.Precision)
+	return ComposeDataType(DataTypeMajorInterval, minor),
+		errors.ErrorCodeNil, ""
+}
+
 // ---------------------------------------------------------------------------
 // Operators
 // ---------------------------------------------------------------------------
@@ -752,7 +843,8 @@ func (n *ConcatOperatorNode) GetType() DataType {
 	return ComposeDataType(DataTypeMajorDynamicBytes, DataTypeMinorDontCare)
 }
 
-// AddOperatorNode is '+'.
+// AddOperatorNode is '+'. Its tagged type is decided by the checker, which
+// also allows TIMESTAMP + INTERVAL and types the result as TIMESTAMP.
 type AddOperatorNode struct {
 	TaggedExprNodeBase
 	BinaryOperatorNode
@@ -760,7 +852,9 @@ type AddOperatorNode struct {
 
 var _ BinaryOperator = (*AddOperatorNode)(nil)
 
-// SubOperatorNode is '-'.
+// SubOperatorNode is '-'. Its tagged type is decided by the checker, which
+// also allows TIMESTAMP - INTERVAL (typed TIMESTAMP) and
+// TIMESTAMP - TIMESTAMP (typed INTERVAL).
 type SubOperatorNode struct {
 	TaggedExprNodeBase
 	BinaryOperatorNode
@@ -909,8 +1003,11 @@ func (n *AssignOperatorNode) GetChildren() []Node {
 // InOperatorNode is 'IN'.
 type InOperatorNode struct {
 	UntaggedExprNodeBase
-	Left  ExprNode
+	Left ExprNode
+	// Right holds the candidate list for 'IN (expr, ...)'. It is mutually
+	// exclusive with Query, which holds the subquery for 'IN (SELECT ...)'.
 	Right []ExprNode
+	Query StmtNode
 }
 
 var _ ExprNode = (*InOperatorNode)(nil)
@@ -922,6 +1019,9 @@ func (n *InOperatorNode) GetType() DataType {
 
 // GetChildren returns a list of child nodes used for traversing.
 func (n *InOperatorNode) GetChildren() []Node {
+	if n.Query != nil {
+		return []Node{n.Left, n.Query}
+	}
 	nodes := make([]Node, 1+len(n.Right))
 	nodes[0] = n.Left
 	for i := 0; i < len(n.Right); i++ {
@@ -932,6 +1032,9 @@ func (n *InOperatorNode) GetChildren() []Node {
 
 // IsConstant returns whether a node is a constant.
 func (n *InOperatorNode) IsConstant() bool {
+	if n.Query != nil {
+		return false
+	}
 	if !n.Left.IsConstant() {
 		return false
 	}
@@ -943,6 +1046,52 @@ func (n *InOperatorNode) IsConstant() bool {
 	return true
 }
 
+// ---------------------------------------------------------------------------
+// Subqueries
+// ---------------------------------------------------------------------------
+
+// SubqueryOperatorNode is a scalar subquery, '(SELECT ...)', used in an
+// expression position.
+type SubqueryOperatorNode struct {
+	TaggedExprNodeBase
+	Query StmtNode
+}
+
+var _ ExprNode = (*SubqueryOperatorNode)(nil)
+
+// GetChildren returns a list of child nodes used for traversing.
+func (n *SubqueryOperatorNode) GetChildren() []Node {
+	return []Node{n.Query}
+}
+
+// IsConstant returns whether a node is a constant.
+func (n *SubqueryOperatorNode) IsConstant() bool {
+	return false
+}
+
+// ExistsOperatorNode is 'EXISTS (SELECT ...)'.
+type ExistsOperatorNode struct {
+	UntaggedExprNodeBase
+	Query StmtNode
+}
+
+var _ ExprNode = (*ExistsOperatorNode)(nil)
+
+// GetType returns the type of 'bool'.
+func (n *ExistsOperatorNode) GetType() DataType {
+	return ComposeDataType(DataTypeMajorBool, DataTypeMinorDontCare)
+}
+
+// GetChildren returns a list of child nodes used for traversing.
+func (n *ExistsOperatorNode) GetChildren() []Node {
+	return []Node{n.Query}
+}
+
+// IsConstant returns whether a node is a constant.
+func (n *ExistsOperatorNode) IsConstant() bool {
+	return false
+}
+
 // ---------------------------------------------------------------------------
 // Function
 // ---------------------------------------------------------------------------
@@ -952,16 +1101,29 @@ type FunctionOperatorNode struct {
 	TaggedExprNodeBase
 	Name *IdentifierNode
 	Args []ExprNode
+	// Over holds the 'OVER (...)' clause when Name is called as a window
+	// function, and is nil for an ordinary function call.
+	Over *WindowOptionNode
 }
 
 var _ ExprNode = (*FunctionOperatorNode)(nil)
 
 // GetChildren returns a list of child nodes used for traversing.
 func (n *FunctionOperatorNode) GetChildren() []Node {
-	nodes := make([]Node, 1+len(n.Args))
+	size := 1 + len(n.Args)
+	if n.Over != nil {
+		size++
+	}
+
+	nodes := make([]Node, size)
 	nodes[0] = n.Name
-	for i := 0; i < len(n.Args); i++ {
-		nodes[i+1] = n.Args[i]
+	idx := 1
+	for i := 0; i < len(n.Args); i, idx = i+1, idx+1 {
+		nodes[idx] = n.Args[i]
+	}
+	if n.Over != nil {
+		nodes[idx] = n.Over
+		idx++
 	}
 	return nodes
 }
@@ -1016,6 +1178,97 @@ func (n *GroupOptionNode) GetChildren() []Node {
 	return []Node{n.Expr}
 }
 
+// WindowOptionNode is the 'OVER (...)' clause attached to a window function
+// call in FunctionOperatorNode.
+type WindowOptionNode struct {
+	NodeBase
+	PartitionBy []ExprNode
+	OrderBy     []*OrderOptionNode
+	// Frame is the optional 'ROWS'/'RANGE BETWEEN ... AND ...' clause that
+	// bounds a running/moving window function (e.g. a moving average). Nil
+	// means the function's default frame applies.
+	Frame *FrameNode
+}
+
+var _ Node = (*WindowOptionNode)(nil)
+
+// GetChildren returns a list of child nodes used for traversing.
+func (n *WindowOptionNode) GetChildren() []Node {
+	size := len(n.PartitionBy) + len(n.OrderBy)
+	if n.Frame != nil {
+		size++
+	}
+	nodes := make([]Node, 0, size)
+	for _, p := range n.PartitionBy {
+		nodes = append(nodes, p)
+	}
+	for _, o := range n.OrderBy {
+		nodes = append(nodes, o)
+	}
+	if n.Frame != nil {
+		nodes = append(nodes, n.Frame)
+	}
+	return nodes
+}
+
+// FrameUnit distinguishes a window frame's counting unit.
+type FrameUnit uint8
+
+// Define valid values for FrameUnit. The zero value is invalid.
+const (
+	FrameUnitRows FrameUnit = iota + 1
+	FrameUnitRange
+)
+
+// FrameBoundKind distinguishes the five bound forms standard SQL allows on
+// either side of a window frame's BETWEEN.
+type FrameBoundKind uint8
+
+// Define valid values for FrameBoundKind. The zero value is invalid.
+const (
+	FrameBoundUnboundedPreceding FrameBoundKind = iota + 1
+	FrameBoundPreceding
+	FrameBoundCurrentRow
+	FrameBoundFollowing
+	FrameBoundUnboundedFollowing
+)
+
+// FrameBoundNode is one side of a window frame's BETWEEN clause. Offset is
+// non-nil only when Kind is FrameBoundPreceding or FrameBoundFollowing,
+// e.g. the '3' in '3 PRECEDING'.
+type FrameBoundNode struct {
+	NodeBase
+	Kind   FrameBoundKind
+	Offset ExprNode
+}
+
+var _ Node = (*FrameBoundNode)(nil)
+
+// GetChildren returns a list of child nodes used for traversing.
+func (n *FrameBoundNode) GetChildren() []Node {
+	if n.Offset == nil {
+		return nil
+	}
+	return []Node{n.Offset}
+}
+
+// FrameNode is a window frame clause -- 'ROWS'/'RANGE BETWEEN start AND
+// end' -- used in WindowOptionNode to bound a running/moving aggregate such
+// as a moving average or a running total.
+type FrameNode struct {
+	NodeBase
+	Unit  FrameUnit
+	Start *FrameBoundNode
+	End   *FrameBoundNode
+}
+
+var _ Node = (*FrameNode)(nil)
+
+// GetChildren returns a list of child nodes used for traversing.
+func (n *FrameNode) GetChildren() []Node {
+	return []Node{n.Start, n.End}
+}
+
 // OffsetOptionNode is 'OFFSET' used in SELECT.
 type OffsetOptionNode struct {
 	NodeBase
@@ -1158,6 +1411,91 @@ func (n *ForeignOptionNode) GetChildren() []Node {
 	return []Node{n.Table, n.Column}
 }
 
+// ---------------------------------------------------------------------------
+// Table references
+// ---------------------------------------------------------------------------
+
+// TableRefNode is satisfied by nodes that can appear in a FROM clause: a
+// single table name or a JOIN combining two other table references.
+type TableRefNode interface {
+	Node
+	isTableRefNode()
+}
+
+// TableNameNode is a single table reference in a FROM clause, optionally
+// aliased.
+type TableNameNode struct {
+	NodeBase
+	Table *IdentifierNode
+	Alias *IdentifierNode
+}
+
+var _ TableRefNode = (*TableNameNode)(nil)
+
+func (n *TableNameNode) isTableRefNode() {}
+
+// GetChildren returns a list of child nodes used for traversing.
+func (n *TableNameNode) GetChildren() []Node {
+	if n.Alias == nil {
+		return []Node{n.Table}
+	}
+	return []Node{n.Table, n.Alias}
+}
+
+// JoinType identifies the kind of JOIN combining two table references.
+type JoinType byte
+
+// Define valid values for JoinType.
+const (
+	JoinTypeInner JoinType = iota
+	JoinTypeLeft
+	JoinTypeRight
+	JoinTypeFull
+	JoinTypeCross
+)
+
+// JoinNode is a JOIN combining two table references in a FROM clause. On is
+// nil for CROSS JOIN, which takes no condition.
+type JoinNode struct {
+	NodeBase
+	Type  JoinType
+	Left  TableRefNode
+	Right TableRefNode
+	On    ExprNode
+}
+
+var _ TableRefNode = (*JoinNode)(nil)
+
+func (n *JoinNode) isTableRefNode() {}
+
+// GetChildren returns a list of child nodes used for traversing.
+func (n *JoinNode) GetChildren() []Node {
+	if n.On == nil {
+		return []Node{n.Left, n.Right}
+	}
+	return []Node{n.Left, n.Right, n.On}
+}
+
+// DerivedTableNode is a subquery used as a table reference in a FROM
+// clause, e.g. '(SELECT ...) AS t'.
+type DerivedTableNode struct {
+	NodeBase
+	Query StmtNode
+	Alias *IdentifierNode
+}
+
+var _ TableRefNode = (*DerivedTableNode)(nil)
+
+func (n *DerivedTableNode) isTableRefNode() {}
+
+// GetChildren returns a list of child nodes used for traversing.
+func (n *DerivedTableNode) GetChildren() []Node {
+	if n.Alias == nil {
+		return []Node{n.Query}
+	}
+	return []Node{n.Query, n.Alias}
+}
+
 // ---------------------------------------------------------------------------
 // Statements
 // ---------------------------------------------------------------------------
@@ -1189,7 +1527,7 @@ type SelectStmtNode struct {
 	NodeBase
 	StmtNodeBase
 	Column []ExprNode
-	Table  *IdentifierNode
+	Table  TableRefNode
 	Where  *WhereOptionNode
 	Group  []*GroupOptionNode
 	Order  []*OrderOptionNode
@@ -1245,11 +1583,40 @@ func (n *SelectStmtNode) GetChildren() []Node {
 	return nodes
 }
 
+// SetOpType identifies the kind of set operation combining two statements.
+type SetOpType byte
+
+// Define valid values for SetOpType.
+const (
+	SetOpUnion SetOpType = iota
+	SetOpIntersect
+	SetOpExcept
+)
+
+// SetOpStmtNode combines two statements with UNION, INTERSECT, or EXCEPT.
+// Left and Right are themselves SelectStmtNode or SetOpStmtNode values,
+// reflecting the left-associative chaining of set operators in the grammar.
+type SetOpStmtNode struct {
+	NodeBase
+	StmtNodeBase
+	Type  SetOpType
+	All   bool
+	Left  StmtNode
+	Right StmtNode
+}
+
+var _ StmtNode = (*SetOpStmtNode)(nil)
+
+// GetChildren returns a list of child nodes used for traversing.
+func (n *SetOpStmtNode) GetChildren() []Node {
+	return []Node{n.Left, n.Right}
+}
+
 // UpdateStmtNode is UPDATE.
 type UpdateStmtNode struct {
 	NodeBase
 	StmtNodeBase
-	Table      *IdentifierNode
+	Table      TableRefNode
 	Assignment []*AssignOperatorNode
 	Where      *WhereOptionNode
 }
@@ -1281,7 +1648,7 @@ func (n *UpdateStmtNode) GetChildren() []Node {
 type DeleteStmtNode struct {
 	NodeBase
 	StmtNodeBase
-	Table *IdentifierNode
+	Table TableRefNode
 	Where *WhereOptionNode
 }
 
@@ -1385,3 +1752,174 @@ func (n *CreateIndexStmtNode) GetChildren() []Node {
 	}
 	return nodes
 }
+
+// AlterTableActionNode is satisfied by any action an ALTER TABLE statement
+// can apply to its table. A single statement carries one or more of these
+// (AlterTableStmtNode.Actions), one per comma-separated clause.
+type AlterTableActionNode interface {
+	Node
+	isAlterTableActionNode()
+}
+
+// AddColumnActionNode is 'ADD COLUMN' used in ALTER TABLE.
+type AddColumnActionNode struct {
+	NodeBase
+	Column *ColumnSchemaNode
+}
+
+var _ AlterTableActionNode = (*AddColumnActionNode)(nil)
+
+func (n *AddColumnActionNode) isAlterTableActionNode() {}
+
+// GetChildren returns a list of child nodes used for traversing.
+func (n *AddColumnActionNode) GetChildren() []Node {
+	return []Node{n.Column}
+}
+
+// DropColumnActionNode is 'DROP COLUMN' used in ALTER TABLE.
+type DropColumnActionNode struct {
+	NodeBase
+	Column *IdentifierNode
+}
+
+var _ AlterTableActionNode = (*DropColumnActionNode)(nil)
+
+func (n *DropColumnActionNode) isAlterTableActionNode() {}
+
+// GetChildren returns a list of child nodes used for traversing.
+func (n *DropColumnActionNode) GetChildren() []Node {
+	return []Node{n.Column}
+}
+
+// RenameColumnActionNode is 'RENAME COLUMN ... TO ...' used in ALTER TABLE.
+type RenameColumnActionNode struct {
+	NodeBase
+	From *IdentifierNode
+	To   *IdentifierNode
+}
+
+var _ AlterTableActionNode = (*RenameColumnActionNode)(nil)
+
+func (n *RenameColumnActionNode) isAlterTableActionNode() {}
+
+// GetChildren returns a list of child nodes used for traversing.
+func (n *RenameColumnActionNode) GetChildren() []Node {
+	return []Node{n.From, n.To}
+}
+
+// RenameTableActionNode is 'RENAME TO' used in ALTER TABLE.
+type RenameTableActionNode struct {
+	NodeBase
+	To *IdentifierNode
+}
+
+var _ AlterTableActionNode = (*RenameTableActionNode)(nil)
+
+func (n *RenameTableActionNode) isAlterTableActionNode() {}
+
+// GetChildren returns a list of child nodes used for traversing.
+func (n *RenameTableActionNode) GetChildren() []Node {
+	return []Node{n.To}
+}
+
+// ModifyColumnActionNode is 'MODIFY COLUMN ... <type>' used in ALTER TABLE,
+// changing a column's declared type in place.
+type ModifyColumnActionNode struct {
+	NodeBase
+	Column   *IdentifierNode
+	DataType TypeNode
+}
+
+var _ AlterTableActionNode = (*ModifyColumnActionNode)(nil)
+
+func (n *ModifyColumnActionNode) isAlterTableActionNode() {}
+
+// GetChildren returns a list of child nodes used for traversing.
+func (n *ModifyColumnActionNode) GetChildren() []Node {
+	return []Node{n.Column, n.DataType}
+}
+
+// AddConstraintActionNode is 'ADD CONSTRAINT name ...' used in ALTER TABLE.
+// Constraint holds the same kind of option node a column's inline
+// Constraint list does (UniqueOptionNode, ForeignOptionNode, ...), now
+// named and attached to the table rather than inlined on a single column.
+type AddConstraintActionNode struct {
+	NodeBase
+	Name       *IdentifierNode
+	Constraint Node
+}
+
+var _ AlterTableActionNode = (*AddConstraintActionNode)(nil)
+
+func (n *AddConstraintActionNode) isAlterTableActionNode() {}
+
+// GetChildren returns a list of child nodes used for traversing.
+func (n *AddConstraintActionNode) GetChildren() []Node {
+	return []Node{n.Name, n.Constraint}
+}
+
+// DropConstraintActionNode is 'DROP CONSTRAINT name' used in ALTER TABLE.
+type DropConstraintActionNode struct {
+	NodeBase
+	Name *IdentifierNode
+}
+
+var _ AlterTableActionNode = (*DropConstraintActionNode)(nil)
+
+func (n *DropConstraintActionNode) isAlterTableActionNode() {}
+
+// GetChildren returns a list of child nodes used for traversing.
+func (n *DropConstraintActionNode) GetChildren() []Node {
+	return []Node{n.Name}
+}
+
+// AlterTableStmtNode is ALTER TABLE. A single statement may carry several
+// comma-separated actions, e.g. "ALTER TABLE t ADD COLUMN a int, DROP
+// COLUMN b".
+type AlterTableStmtNode struct {
+	NodeBase
+	StmtNodeBase
+	Table   *IdentifierNode
+	Actions []AlterTableActionNode
+}
+
+var _ StmtNode = (*AlterTableStmtNode)(nil)
+
+// GetChildren returns a list of child nodes used for traversing.
+func (n *AlterTableStmtNode) GetChildren() []Node {
+	nodes := make([]Node, 1+len(n.Actions))
+	nodes[0] = n.Table
+	for i := 0; i < len(n.Actions); i++ {
+		nodes[i+1] = n.Actions[i]
+	}
+	return nodes
+}
+
+// CreateViewStmtNode is CREATE VIEW.
+type CreateViewStmtNode struct {
+	NodeBase
+	StmtNodeBase
+	View  *IdentifierNode
+	Query StmtNode
+}
+
+var _ StmtNode = (*CreateViewStmtNode)(nil)
+
+// GetChildren returns a list of child nodes used for traversing.
+func (n *CreateViewStmtNode) GetChildren() []Node {
+	return []Node{n.View, n.Query}
+}
+
+// DropViewStmtNode is DROP VIEW.
+type DropViewStmtNode struct {
+	NodeBase
+	StmtNodeBase
+	View *IdentifierNode
+}
+
+var _ StmtNode = (*DropViewStmtNode)(nil)
+
+// GetChildren returns a list of child nodes used for traversing.
+func (n *DropViewStmtNode) GetChildren() []Node {
+	return []Node{n.View}
+}
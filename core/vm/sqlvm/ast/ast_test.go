@@ -0,0 +1,55 @@
+package ast
+
+import "testing"
+
+// TestUpdateDeleteTableAcceptsAlias guards against UpdateStmtNode.Table and
+// DeleteStmtNode.Table regressing to a bare *IdentifierNode: both must
+// accept anything satisfying TableRefNode, the same as SelectStmtNode.Table,
+// so UPDATE/DELETE can target an aliased table.
+func TestUpdateDeleteTableAcceptsAlias(t *testing.T) {
+	aliased := &TableNameNode{
+		Table: &IdentifierNode{Name: []byte("t")},
+		Alias: &IdentifierNode{Name: []byte("u")},
+	}
+
+	update := &UpdateStmtNode{Table: aliased}
+	if got := update.GetChildren()[0]; got != Node(aliased) {
+		t.Errorf("UpdateStmtNode.GetChildren()[0] = %v, want aliased table ref", got)
+	}
+
+	del := &DeleteStmtNode{Table: aliased}
+	if got := del.GetChildren()[0]; got != Node(aliased) {
+		t.Errorf("DeleteStmtNode.GetChildren()[0] = %v, want aliased table ref", got)
+	}
+}
+
+// TestQualifiedIdentifierFormatsAndRewrites checks that IdentifierNode.Qualifier
+// -- needed to disambiguate a column between two joined tables -- round-trips
+// through Format as "qualifier"."name" and is itself reachable by
+// Rewrite/GetChildren, not just carried along inertly.
+func TestQualifiedIdentifierFormatsAndRewrites(t *testing.T) {
+	col := &IdentifierNode{Qualifier: &IdentifierNode{Name: []byte("a")}, Name: []byte("id")}
+
+	out, err := Format(col)
+	if err != nil {
+		t.Fatalf("Format: %v", err)
+	}
+	const want = `"a"."id"`
+	if string(out) != want {
+		t.Errorf("Format(a.id) = %q, want %q", out, want)
+	}
+
+	if got := col.GetChildren(); len(got) != 1 || got[0] != Node(col.Qualifier) {
+		t.Errorf("GetChildren() = %v, want [Qualifier]", got)
+	}
+
+	renamed := Rewrite(col, func(n Node) Node {
+		if id, ok := n.(*IdentifierNode); ok && string(id.Name) == "a" {
+			return &IdentifierNode{Name: []byte("b")}
+		}
+		return n
+	}).(*IdentifierNode)
+	if string(renamed.Qualifier.Name) != "b" {
+		t.Errorf("Qualifier.Name = %q, want %q", renamed.Qualifier.Name, "b")
+	}
+}
@@ -0,0 +1,504 @@
+package ast
+
+import (
+	"bytes"
+
+	"github.com/dexon-foundation/decimal"
+
+	"github.com/dexon-foundation/dexon/core/vm/sqlvm/errors"
+)
+
+// FoldError records a diagnostic produced while constant-folding a subtree.
+// It is attached to the node where folding was abandoned so the planner can
+// choose to reject the statement or defer evaluation to run time.
+type FoldError struct {
+	Node    Node
+	Code    errors.ErrorCode
+	Message string
+}
+
+// FoldConstants walks n bottom-up and replaces any ExprNode whose
+// IsConstant() is true, and whose operands have already folded down to
+// literal value nodes, with the corresponding BoolValueNode /
+// IntegerValueNode / DecimalValueNode / BytesValueNode / NullValueNode.
+// Division by zero, and a constant CAST whose value is out of range for its
+// target type, never panic: the offending subtree is left in place and a
+// FoldError describing it is appended to the returned slice instead, so the
+// caller decides whether to reject or defer the original expression. The
+// results of folded +, -, *, /, %, unary + and unary - are not range-checked
+// against the node's type, so an overflowing one folds without a FoldError.
+func FoldConstants(n Node) (Node, []FoldError) {
+	var errs []FoldError
+	result := Rewrite(n, func(node Node) Node {
+		expr, ok := node.(ExprNode)
+		if !ok || !expr.IsConstant() {
+			return node
+		}
+		folded, foldErr := foldExpr(expr)
+		if foldErr != nil {
+			errs = append(errs, *foldErr)
+			return node
+		}
+		if folded == nil {
+			return node
+		}
+		UpdatePosition(folded, node, node)
+		return folded
+	})
+	return result, errs
+}
+
+// foldExpr evaluates a single constant ExprNode whose children have already
+// been folded. It returns (nil, nil) when n is not a constant-foldable
+// operator (e.g. it is already a literal, or a non-constant function call).
+func foldExpr(n ExprNode) (ExprNode, *FoldError) {
+	switch node := n.(type) {
+	case *AddOperatorNode:
+		return foldArith(node, node.Object, node.Subject, decimal.Decimal.Add)
+	case *SubOperatorNode:
+		return foldArith(node, node.Object, node.Subject, decimal.Decimal.Sub)
+	case *MulOperatorNode:
+		return foldArith(node, node.Object, node.Subject, decimal.Decimal.Mul)
+	case *DivOperatorNode:
+		return foldDivMod(node, node.Object, node.Subject, decimal.Decimal.Div)
+	case *ModOperatorNode:
+		return foldDivMod(node, node.Object, node.Subject, decimal.Decimal.Mod)
+	case *PosOperatorNode:
+		return foldUnaryArith(node, node.Target, func(a decimal.Decimal) decimal.Decimal { return a })
+	case *NegOperatorNode:
+		return foldUnaryArith(node, node.Target, decimal.Decimal.Neg)
+	case *AndOperatorNode:
+		return foldLogical(node.Object, node.Subject, BoolValue.And)
+	case *OrOperatorNode:
+		return foldLogical(node.Object, node.Subject, BoolValue.Or)
+	case *NotOperatorNode:
+		a, ok := literalBool(node.Target)
+		if !ok {
+			return nil, nil
+		}
+		return boolValueNode(a.Not()), nil
+	case *EqualOperatorNode:
+		return foldEquality(node.Object, node.Subject, true)
+	case *NotEqualOperatorNode:
+		return foldEquality(node.Object, node.Subject, false)
+	case *GreaterOperatorNode:
+		return foldOrder(node.Object, node.Subject, func(c int) bool { return c > 0 })
+	case *GreaterOrEqualOperatorNode:
+		return foldOrder(node.Object, node.Subject, func(c int) bool { return c >= 0 })
+	case *LessOperatorNode:
+		return foldOrder(node.Object, node.Subject, func(c int) bool { return c < 0 })
+	case *LessOrEqualOperatorNode:
+		return foldOrder(node.Object, node.Subject, func(c int) bool { return c <= 0 })
+	case *ConcatOperatorNode:
+		return foldConcat(node)
+	case *IsOperatorNode:
+		return foldIs(node)
+	case *InOperatorNode:
+		return foldIn(node)
+	case *LikeOperatorNode:
+		return foldLike(node)
+	case *ParenOperatorNode:
+		if isLiteral(node.Target) {
+			return node.Target, nil
+		}
+		return nil, nil
+	case *CastOperatorNode:
+		return foldCast(node)
+	default:
+		return nil, nil
+	}
+}
+
+// literalDecimal extracts the decimal value backing a folded literal node.
+// ok is false if n is not a node foldExpr already reduced to a literal.
+func literalDecimal(n ExprNode) (v decimal.Decimal, isNull, ok bool) {
+	switch lit := n.(type) {
+	case *IntegerValueNode:
+		return lit.V, false, true
+	case *DecimalValueNode:
+		return lit.V, false, true
+	case *NullValueNode:
+		return decimal.Decimal{}, true, true
+	}
+	return decimal.Decimal{}, false, false
+}
+
+// literalBool extracts the three-valued boolean behind a folded literal
+// node. ok is false if n is not a node foldExpr already reduced to a
+// literal.
+func literalBool(n ExprNode) (v BoolValue, ok bool) {
+	switch lit := n.(type) {
+	case *BoolValueNode:
+		return boolValueFromBool(lit.V), true
+	case *NullValueNode:
+		return BoolValueUnknown, true
+	}
+	return 0, false
+}
+
+// literalBytes extracts the bytes behind a folded literal node. ok is false
+// if n is not a node foldExpr already reduced to a literal.
+func literalBytes(n ExprNode) (v []byte, isNull, ok bool) {
+	switch lit := n.(type) {
+	case *BytesValueNode:
+		return lit.V, false, true
+	case *NullValueNode:
+		return nil, true, true
+	}
+	return nil, false, false
+}
+
+// isLiteral reports whether n is already one of the value nodes FoldConstants
+// produces.
+func isLiteral(n Node) bool {
+	switch n.(type) {
+	case *BoolValueNode, *IntegerValueNode, *DecimalValueNode, *BytesValueNode,
+		*NullValueNode:
+		return true
+	}
+	return false
+}
+
+func boolValueNode(v BoolValue) ExprNode {
+	if v == BoolValueUnknown {
+		return &NullValueNode{TaggedExprNodeBase: TaggedExprNodeBase{
+			Type: ComposeDataType(DataTypeMajorBool, DataTypeMinorDontCare),
+		}}
+	}
+	return &BoolValueNode{V: v == BoolValueTrue}
+}
+
+// isIntegerLiteral reports whether n is a literal IntegerValueNode, and if
+// so, whether it carries IsAddress -- used to decide whether an arithmetic
+// fold should preserve the integer representation of its operands (and
+// their address-ness) rather than always widening to DecimalValueNode.
+func isIntegerLiteral(n ExprNode) (isInteger, isAddress bool) {
+	lit, ok := n.(*IntegerValueNode)
+	if !ok {
+		return false, false
+	}
+	return true, lit.IsAddress
+}
+
+// numericValueNode builds the literal node for a folded arithmetic result:
+// an IntegerValueNode, preserving isAddress, when isInteger is true (both
+// operands folded to integer literals), a DecimalValueNode otherwise. This
+// matches FoldConstants's own doc comment, which promises an IntegerValueNode
+// result, not just a DecimalValueNode, when the inputs were integers.
+func numericValueNode(typ DataType, isInteger, isAddress bool, v decimal.Decimal) ExprNode {
+	if isInteger {
+		return &IntegerValueNode{
+			TaggedExprNodeBase: TaggedExprNodeBase{Type: typ},
+			IsAddress:          isAddress,
+			V:                  v,
+		}
+	}
+	return &DecimalValueNode{TaggedExprNodeBase: TaggedExprNodeBase{Type: typ}, V: v}
+}
+
+func foldArith(
+	n ExprNode, obj, subj ExprNode,
+	apply func(a, b decimal.Decimal) decimal.Decimal,
+) (ExprNode, *FoldError) {
+	a, aNull, aOk := literalDecimal(obj)
+	b, bNull, bOk := literalDecimal(subj)
+	if !aOk || !bOk {
+		return nil, nil
+	}
+	if aNull || bNull {
+		return &NullValueNode{TaggedExprNodeBase: TaggedExprNodeBase{Type: n.GetType()}}, nil
+	}
+	aInt, aAddr := isIntegerLiteral(obj)
+	bInt, bAddr := isIntegerLiteral(subj)
+	return numericValueNode(n.GetType(), aInt && bInt, aAddr || bAddr, apply(a, b)), nil
+}
+
+func foldDivMod(
+	n ExprNode, obj, subj ExprNode,
+	apply func(a, b decimal.Decimal) decimal.Decimal,
+) (ExprNode, *FoldError) {
+	a, aNull, aOk := literalDecimal(obj)
+	b, bNull, bOk := literalDecimal(subj)
+	if !aOk || !bOk {
+		return nil, nil
+	}
+	if aNull || bNull {
+		return &NullValueNode{TaggedExprNodeBase: TaggedExprNodeBase{Type: n.GetType()}}, nil
+	}
+	if b.Sign() == 0 {
+		return nil, &FoldError{
+			Node: n, Code: errors.ErrorCodeDivByZero,
+			Message: "division by zero during constant folding",
+		}
+	}
+	aInt, aAddr := isIntegerLiteral(obj)
+	bInt, bAddr := isIntegerLiteral(subj)
+	return numericValueNode(n.GetType(), aInt && bInt, aAddr || bAddr, apply(a, b)), nil
+}
+
+func foldUnaryArith(
+	n ExprNode, target ExprNode, apply func(a decimal.Decimal) decimal.Decimal,
+) (ExprNode, *FoldError) {
+	a, aNull, aOk := literalDecimal(target)
+	if !aOk {
+		return nil, nil
+	}
+	if aNull {
+		return &NullValueNode{TaggedExprNodeBase: TaggedExprNodeBase{Type: n.GetType()}}, nil
+	}
+	isInt, isAddr := isIntegerLiteral(target)
+	return numericValueNode(n.GetType(), isInt, isAddr, apply(a)), nil
+}
+
+func foldLogical(obj, subj ExprNode, apply func(a, b BoolValue) BoolValue) (ExprNode, *FoldError) {
+	a, aOk := literalBool(obj)
+	b, bOk := literalBool(subj)
+	if !aOk || !bOk {
+		return nil, nil
+	}
+	return boolValueNode(apply(a, b)), nil
+}
+
+func foldEquality(obj, subj ExprNode, wantEqual bool) (ExprNode, *FoldError) {
+	if a, aNull, aOk := literalDecimal(obj); aOk {
+		b, bNull, bOk := literalDecimal(subj)
+		if !bOk {
+			return nil, nil
+		}
+		if aNull || bNull {
+			return boolValueNode(BoolValueUnknown), nil
+		}
+		return boolValueNode(boolValueFromBool(a.Equal(b) == wantEqual)), nil
+	}
+	if a, aNull, aOk := literalBytes(obj); aOk {
+		b, bNull, bOk := literalBytes(subj)
+		if !bOk {
+			return nil, nil
+		}
+		if aNull || bNull {
+			return boolValueNode(BoolValueUnknown), nil
+		}
+		return boolValueNode(boolValueFromBool(bytes.Equal(a, b) == wantEqual)), nil
+	}
+	if a, aOk := literalBool(obj); aOk {
+		b, bOk := literalBool(subj)
+		if !bOk {
+			return nil, nil
+		}
+		if a == BoolValueUnknown || b == BoolValueUnknown {
+			return boolValueNode(BoolValueUnknown), nil
+		}
+		return boolValueNode(boolValueFromBool((a == b) == wantEqual)), nil
+	}
+	return nil, nil
+}
+
+func foldOrder(obj, subj ExprNode, pred func(cmp int) bool) (ExprNode, *FoldError) {
+	a, aNull, aOk := literalDecimal(obj)
+	b, bNull, bOk := literalDecimal(subj)
+	if !aOk || !bOk {
+		return nil, nil
+	}
+	if aNull || bNull {
+		return boolValueNode(BoolValueUnknown), nil
+	}
+	return boolValueNode(boolValueFromBool(pred(a.Cmp(b)))), nil
+}
+
+func foldConcat(n *ConcatOperatorNode) (ExprNode, *FoldError) {
+	a, aNull, aOk := literalBytes(n.Object)
+	b, bNull, bOk := literalBytes(n.Subject)
+	if !aOk || !bOk {
+		return nil, nil
+	}
+	if aNull || bNull {
+		return &NullValueNode{TaggedExprNodeBase: TaggedExprNodeBase{Type: n.GetType()}}, nil
+	}
+	v := make([]byte, 0, len(a)+len(b))
+	v = append(v, a...)
+	v = append(v, b...)
+	return &BytesValueNode{TaggedExprNodeBase: TaggedExprNodeBase{Type: n.GetType()}, V: v}, nil
+}
+
+// foldIs evaluates 'expr IS NULL'. Unlike every other operator it never
+// itself produces NULL, even when expr's own constancy could not be
+// determined structurally.
+func foldIs(n *IsOperatorNode) (ExprNode, *FoldError) {
+	if _, ok := n.Subject.(*NullValueNode); !ok {
+		return nil, nil
+	}
+	switch n.Object.(type) {
+	case *NullValueNode:
+		return boolValueNode(BoolValueTrue), nil
+	case *BoolValueNode, *IntegerValueNode, *DecimalValueNode, *BytesValueNode:
+		return boolValueNode(BoolValueFalse), nil
+	default:
+		return nil, nil
+	}
+}
+
+// foldIn evaluates 'left IN (right...)' against decimal-backed literals,
+// returning UNKNOWN rather than FALSE when a NULL is present among operands
+// that didn't match, per SQL's three-valued semantics.
+func foldIn(n *InOperatorNode) (ExprNode, *FoldError) {
+	a, aNull, aOk := literalDecimal(n.Left)
+	if !aOk {
+		return nil, nil
+	}
+	if aNull {
+		return boolValueNode(BoolValueUnknown), nil
+	}
+	sawUnknown := false
+	for _, r := range n.Right {
+		b, bNull, bOk := literalDecimal(r)
+		if !bOk {
+			return nil, nil
+		}
+		if bNull {
+			sawUnknown = true
+			continue
+		}
+		if a.Equal(b) {
+			return boolValueNode(BoolValueTrue), nil
+		}
+	}
+	if sawUnknown {
+		return boolValueNode(BoolValueUnknown), nil
+	}
+	return boolValueNode(BoolValueFalse), nil
+}
+
+func foldLike(n *LikeOperatorNode) (ExprNode, *FoldError) {
+	str, strNull, strOk := literalBytes(n.Object)
+	pat, patNull, patOk := literalBytes(n.Subject)
+	if !strOk || !patOk {
+		return nil, nil
+	}
+	var escape byte
+	hasEscape := false
+	if n.Escape != nil {
+		e, eNull, eOk := literalBytes(n.Escape)
+		if !eOk {
+			return nil, nil
+		}
+		if eNull {
+			return boolValueNode(BoolValueUnknown), nil
+		}
+		if len(e) != 1 {
+			return nil, &FoldError{
+				Node: n, Code: errors.ErrorCodeInvalidBytesSize,
+				Message: "LIKE ESCAPE must be exactly one byte",
+			}
+		}
+		escape, hasEscape = e[0], true
+	}
+	if strNull || patNull {
+		return boolValueNode(BoolValueUnknown), nil
+	}
+	return boolValueNode(boolValueFromBool(likeMatch(str, pat, escape, hasEscape))), nil
+}
+
+// likeMatch reports whether s matches the SQL LIKE pattern p, where '%'
+// matches any run of bytes (including none), '_' matches exactly one byte,
+// and, if hasEscape, escape immediately preceding a wildcard matches it
+// literally instead.
+func likeMatch(s, p []byte, escape byte, hasEscape bool) bool {
+	sp, pp := 0, 0
+	starIdx, starS := -1, 0
+	for sp < len(s) {
+		switch {
+		case pp < len(p) && hasEscape && p[pp] == escape && pp+1 < len(p):
+			if s[sp] != p[pp+1] {
+				if starIdx < 0 {
+					return false
+				}
+				pp = starIdx + 1
+				starS++
+				sp = starS
+				continue
+			}
+			sp++
+			pp += 2
+		case pp < len(p) && p[pp] == '_':
+			sp++
+			pp++
+		case pp < len(p) && p[pp] == '%':
+			starIdx, starS = pp, sp
+			pp++
+		case pp < len(p) && p[pp] == s[sp]:
+			sp++
+			pp++
+		case starIdx >= 0:
+			pp = starIdx + 1
+			starS++
+			sp = starS
+		default:
+			return false
+		}
+	}
+	for pp < len(p) && p[pp] == '%' {
+		pp++
+	}
+	return pp == len(p)
+}
+
+// foldCast evaluates 'CAST(expr AS type)', honoring the target type's own
+// validation and, for decimal-backed targets, the same range check
+// DecimalEncodeChecked applies via decimalMinMaxMap.
+func foldCast(n *CastOperatorNode) (ExprNode, *FoldError) {
+	targetType, code, msg := n.TargetType.GetType()
+	if code != errors.ErrorCodeNil {
+		return nil, &FoldError{Node: n, Code: code, Message: msg}
+	}
+	major, minor := DecomposeDataType(targetType)
+
+	switch major {
+	case DataTypeMajorBool:
+		v, ok := literalBool(n.SourceExpr)
+		if !ok {
+			return nil, nil
+		}
+		return boolValueNode(v), nil
+	case DataTypeMajorFixedBytes, DataTypeMajorDynamicBytes:
+		b, isNull, ok := literalBytes(n.SourceExpr)
+		if !ok {
+			return nil, nil
+		}
+		if isNull {
+			return &NullValueNode{TaggedExprNodeBase: TaggedExprNodeBase{Type: targetType}}, nil
+		}
+		return &BytesValueNode{TaggedExprNodeBase: TaggedExprNodeBase{Type: targetType}, V: b}, nil
+	default:
+		d, isNull, ok := literalDecimal(n.SourceExpr)
+		if !ok {
+			return nil, nil
+		}
+		if isNull {
+			return &NullValueNode{TaggedExprNodeBase: TaggedExprNodeBase{Type: targetType}}, nil
+		}
+		if major == DataTypeMajorInt || major == DataTypeMajorUint {
+			// int/uint carry no fractional digits: CAST truncates towards
+			// zero, same as DecimalEncode packing an integer column.
+			d = d.Truncate(0)
+		}
+		// DecimalEncodeChecked range-checks a fixed/ufixed value after
+		// Shift(minor), since that is the form actually packed into bytes;
+		// mirror that here so an overflow too subtle to see in d itself but
+		// exposed once scaled by FractionalDigits is still caught at fold
+		// time instead of silently truncating at encode time.
+		shifted := d
+		if major.IsFixedRange() || major.IsUfixedRange() {
+			shifted = d.Shift(int32(minor))
+		}
+		if min, max, hasRange := targetType.GetMinMax(); hasRange &&
+			(shifted.LessThan(min) || shifted.GreaterThan(max)) {
+			return nil, &FoldError{
+				Node: n, Code: errors.ErrorCodeOverflow,
+				Message: "constant CAST out of range for target type",
+			}
+		}
+		isAddress := major == DataTypeMajorAddress
+		isInt := major == DataTypeMajorInt || major == DataTypeMajorUint || isAddress
+		return numericValueNode(targetType, isInt, isAddress, d), nil
+	}
+}
@@ -0,0 +1,104 @@
+package ast
+
+import (
+	"testing"
+
+	"github.com/dexon-foundation/decimal"
+
+	"github.com/dexon-foundation/dexon/core/vm/sqlvm/errors"
+)
+
+// TestFoldConstantsArith checks the common case: a literal + literal
+// expression folds down to a single literal node.
+func TestFoldConstantsArith(t *testing.T) {
+	add := &AddOperatorNode{BinaryOperatorNode: BinaryOperatorNode{Object: intLit(1), Subject: intLit(2)}}
+
+	out, errs := FoldConstants(add)
+	if len(errs) != 0 {
+		t.Fatalf("FoldConstants: unexpected errors %v", errs)
+	}
+	lit, ok := out.(*IntegerValueNode)
+	if !ok {
+		t.Fatalf("FoldConstants(1+2) = %#v, want *IntegerValueNode", out)
+	}
+	if !lit.V.Equal(intLit(3).V) {
+		t.Errorf("FoldConstants(1+2).V = %s, want 3", lit.V)
+	}
+}
+
+// TestFoldConstantsNullPropagates checks that folding an arithmetic
+// expression with a NULL operand produces a NULL literal instead of
+// attempting the decimal operation.
+func TestFoldConstantsNullPropagates(t *testing.T) {
+	add := &AddOperatorNode{BinaryOperatorNode: BinaryOperatorNode{
+		Object:  intLit(1),
+		Subject: &NullValueNode{TaggedExprNodeBase: TaggedExprNodeBase{Type: intLit(1).GetType()}},
+	}}
+
+	out, errs := FoldConstants(add)
+	if len(errs) != 0 {
+		t.Fatalf("FoldConstants: unexpected errors %v", errs)
+	}
+	if _, ok := out.(*NullValueNode); !ok {
+		t.Errorf("FoldConstants(1+NULL) = %#v, want *NullValueNode", out)
+	}
+}
+
+// TestFoldConstantsArithMixedStaysDecimal checks that folding an arithmetic
+// expression with one decimal-literal operand widens to a DecimalValueNode
+// even though the other operand is an integer literal, rather than
+// incorrectly narrowing the mixed result to IntegerValueNode.
+func TestFoldConstantsArithMixedStaysDecimal(t *testing.T) {
+	add := &AddOperatorNode{BinaryOperatorNode: BinaryOperatorNode{
+		Object:  intLit(1),
+		Subject: &DecimalValueNode{V: decimal.New(15, -1)}, // 1.5
+	}}
+
+	out, errs := FoldConstants(add)
+	if len(errs) != 0 {
+		t.Fatalf("FoldConstants: unexpected errors %v", errs)
+	}
+	if _, ok := out.(*DecimalValueNode); !ok {
+		t.Errorf("FoldConstants(1+1.5) = %#v, want *DecimalValueNode", out)
+	}
+}
+
+// TestFoldConstantsCastToIntProducesIntegerValueNode checks that CAST(...
+// AS int64) folds to an IntegerValueNode, matching the target type, rather
+// than a DecimalValueNode.
+func TestFoldConstantsCastToIntProducesIntegerValueNode(t *testing.T) {
+	cast := &CastOperatorNode{
+		SourceExpr: &DecimalValueNode{V: decimal.New(15, -1)}, // 1.5
+		TargetType: &IntTypeNode{Size: 64},
+	}
+
+	out, errs := FoldConstants(cast)
+	if len(errs) != 0 {
+		t.Fatalf("FoldConstants: unexpected errors %v", errs)
+	}
+	lit, ok := out.(*IntegerValueNode)
+	if !ok {
+		t.Fatalf("FoldConstants(CAST(1.5 AS int64)) = %#v, want *IntegerValueNode", out)
+	}
+	if !lit.V.Equal(decimal.New(1, 0)) {
+		t.Errorf("FoldConstants(CAST(1.5 AS int64)).V = %s, want 1", lit.V)
+	}
+}
+
+// TestFoldConstantsDivByZero checks that constant-folding a division by a
+// literal zero reports a FoldError and leaves the original subtree in place
+// rather than panicking or silently producing a bogus result.
+func TestFoldConstantsDivByZero(t *testing.T) {
+	div := &DivOperatorNode{BinaryOperatorNode: BinaryOperatorNode{Object: intLit(1), Subject: intLit(0)}}
+
+	out, errs := FoldConstants(div)
+	if len(errs) != 1 {
+		t.Fatalf("FoldConstants: got %d errors, want 1", len(errs))
+	}
+	if errs[0].Code != errors.ErrorCodeDivByZero {
+		t.Errorf("FoldConstants(1/0) error code = %v, want ErrorCodeDivByZero", errs[0].Code)
+	}
+	if out != div {
+		t.Errorf("FoldConstants(1/0) = %#v, want the original node left in place", out)
+	}
+}
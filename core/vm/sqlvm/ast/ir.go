@@ -0,0 +1,372 @@
+package ast
+
+import "fmt"
+
+// Register identifies a VM register holding an intermediate value produced
+// while evaluating an expression.
+type Register uint16
+
+// IROp identifies the operation a single IRInstruction performs.
+type IROp uint8
+
+// Define valid values for IROp.
+//
+// Add/Sub/Mul/Div/Mod and Equal/NotEqual each come in type-specialized
+// variants instead of one generic op per operator: Int/Uint/Fixed/Ufixed
+// arithmetic runs through completely different VM code paths
+// (wrapping/overflow-checked machine integers versus the arbitrary-precision
+// decimal library), and Bool/Bytes/Decimal equality compare different Value
+// fields (see Value.Equal), so leaving the choice to a runtime type switch
+// on every instruction would redo work the lowerer already has the type
+// information to do once. Any other major (address, temporal, dynamic
+// bytes used non-equality) has no specialized opcode and keeps using the
+// untyped op.
+const (
+	IROpLoadConst IROp = iota
+	IROpLoadColumn
+	IROpPos
+	IROpNeg
+	IROpNot
+	IROpAnd
+	IROpOr
+	IROpEqual
+	IROpEqualBool
+	IROpEqualBytes
+	IROpEqualDecimal
+	IROpNotEqual
+	IROpNotEqualBool
+	IROpNotEqualBytes
+	IROpNotEqualDecimal
+	IROpGreater
+	IROpGreaterOrEqual
+	IROpLess
+	IROpLessOrEqual
+	IROpConcat
+	IROpAdd
+	IROpAddInt
+	IROpAddUint
+	IROpAddFixed
+	IROpAddUfixed
+	IROpSub
+	IROpSubInt
+	IROpSubUint
+	IROpSubFixed
+	IROpSubUfixed
+	IROpMul
+	IROpMulInt
+	IROpMulUint
+	IROpMulFixed
+	IROpMulUfixed
+	IROpDiv
+	IROpDivInt
+	IROpDivUint
+	IROpDivFixed
+	IROpDivUfixed
+	IROpMod
+	IROpModInt
+	IROpModUint
+	IROpModFixed
+	IROpModUfixed
+	IROpIs
+	IROpLike
+	// IROpBuildSet and IROpInSet replace the single generic "IN" op for a
+	// large IN (...) list: IROpBuildSet materializes its Args into a set
+	// register, and IROpInSet tests membership. See lowerIn.
+	IROpBuildSet
+	IROpInSet
+	IROpCast
+	IROpCall
+)
+
+// IRInstruction is one flat, three-address instruction: Dst := Op(Args...).
+type IRInstruction struct {
+	Op   IROp
+	Dst  Register
+	Args []Register
+	// Imm carries operator-specific immediate data not itself computed by a
+	// register: the literal ExprNode for IROpLoadConst, the column name for
+	// IROpLoadColumn, the target TypeNode for IROpCast, the function name
+	// for IROpCall, and whether an explicit ESCAPE was supplied for
+	// IROpLike. It is nil for every other op, including IROpBuildSet (whose
+	// members are its Args) and IROpInSet (whose Args are [value, set
+	// register]).
+	Imm  interface{}
+	Type DataType
+}
+
+// IRProgram is the flattened form of an expression tree: a sequence of
+// instructions writing into monotonically increasing registers, ending with
+// the register holding the overall result. Unlike the tree it was lowered
+// from, the VM can execute a program by stepping through Instructions once,
+// without re-walking nodes or re-dispatching on their concrete type.
+type IRProgram struct {
+	Instructions []IRInstruction
+	Result       Register
+}
+
+// Lower flattens expr into a register-based IRProgram.
+func Lower(expr ExprNode) (*IRProgram, error) {
+	l := &irLowerer{}
+	dst, err := l.lower(expr)
+	if err != nil {
+		return nil, err
+	}
+	return &IRProgram{Instructions: l.instructions, Result: dst}, nil
+}
+
+// irLowerer accumulates instructions while walking an ExprNode tree.
+type irLowerer struct {
+	instructions []IRInstruction
+	next         Register
+}
+
+func (l *irLowerer) emit(op IROp, typ DataType, imm interface{}, args ...Register) Register {
+	dst := l.next
+	l.next++
+	l.instructions = append(l.instructions, IRInstruction{
+		Op: op, Dst: dst, Args: args, Imm: imm, Type: typ,
+	})
+	return dst
+}
+
+func (l *irLowerer) lowerUnary(op IROp, typ DataType, target ExprNode) (Register, error) {
+	t, err := l.lower(target)
+	if err != nil {
+		return 0, err
+	}
+	return l.emit(op, typ, nil, t), nil
+}
+
+func (l *irLowerer) lowerBinary(op IROp, typ DataType, obj, subj ExprNode) (Register, error) {
+	o, err := l.lower(obj)
+	if err != nil {
+		return 0, err
+	}
+	s, err := l.lower(subj)
+	if err != nil {
+		return 0, err
+	}
+	return l.emit(op, typ, nil, o, s), nil
+}
+
+// arithOps holds the [untyped, Int, Uint, Fixed, Ufixed] opcode variants for
+// one arithmetic operator, indexed by the arithKind of the operation's
+// result type.
+type arithOps [5]IROp
+
+var (
+	addOps = arithOps{IROpAdd, IROpAddInt, IROpAddUint, IROpAddFixed, IROpAddUfixed}
+	subOps = arithOps{IROpSub, IROpSubInt, IROpSubUint, IROpSubFixed, IROpSubUfixed}
+	mulOps = arithOps{IROpMul, IROpMulInt, IROpMulUint, IROpMulFixed, IROpMulUfixed}
+	divOps = arithOps{IROpDiv, IROpDivInt, IROpDivUint, IROpDivFixed, IROpDivUfixed}
+	modOps = arithOps{IROpMod, IROpModInt, IROpModUint, IROpModFixed, IROpModUfixed}
+)
+
+// arithKind classifies a DataType's major for the arithOps table above.
+// arithGeneric covers every major with no machine-arithmetic opcode of its
+// own (address, temporal, bytes, bool); arithmetic involving those falls
+// back to the untyped op, same as before IR ops were type-specialized.
+type arithKind uint8
+
+const (
+	arithGeneric arithKind = iota
+	arithInt
+	arithUint
+	arithFixed
+	arithUfixed
+)
+
+func classifyArith(typ DataType) arithKind {
+	major, _ := DecomposeDataType(typ)
+	switch {
+	case major == DataTypeMajorInt:
+		return arithInt
+	case major == DataTypeMajorUint:
+		return arithUint
+	case major.IsFixedRange():
+		return arithFixed
+	case major.IsUfixedRange():
+		return arithUfixed
+	default:
+		return arithGeneric
+	}
+}
+
+func (ops arithOps) forType(typ DataType) IROp {
+	return ops[classifyArith(typ)]
+}
+
+// eqOps holds the [Decimal, Bool, Bytes] opcode variants for an equality
+// operator, indexed by the eqKind of the compared operands' type.
+type eqOps [3]IROp
+
+var (
+	equalOps    = eqOps{IROpEqualDecimal, IROpEqualBool, IROpEqualBytes}
+	notEqualOps = eqOps{IROpNotEqualDecimal, IROpNotEqualBool, IROpNotEqualBytes}
+)
+
+// eqKind classifies a DataType's major for the eqOps table above, mirroring
+// the three-way switch Value.Equal already uses at runtime to decide which
+// of its fields (Bool, Bytes, Decimal) to compare.
+type eqKind uint8
+
+const (
+	eqDecimal eqKind = iota
+	eqBool
+	eqBytes
+)
+
+func classifyEq(typ DataType) eqKind {
+	major, _ := DecomposeDataType(typ)
+	switch major {
+	case DataTypeMajorBool:
+		return eqBool
+	case DataTypeMajorFixedBytes, DataTypeMajorDynamicBytes:
+		return eqBytes
+	default:
+		return eqDecimal
+	}
+}
+
+func (ops eqOps) forType(typ DataType) IROp {
+	return ops[classifyEq(typ)]
+}
+
+func (l *irLowerer) lower(n ExprNode) (Register, error) {
+	switch node := n.(type) {
+	case *IdentifierNode:
+		return l.emit(IROpLoadColumn, node.GetType(), node.Name), nil
+	case *BoolValueNode, *IntegerValueNode, *DecimalValueNode, *BytesValueNode,
+		*NullValueNode, *AnyValueNode, *DefaultValueNode:
+		return l.emit(IROpLoadConst, n.GetType(), n), nil
+	case *PosOperatorNode:
+		return l.lowerUnary(IROpPos, n.GetType(), node.Target)
+	case *NegOperatorNode:
+		return l.lowerUnary(IROpNeg, n.GetType(), node.Target)
+	case *NotOperatorNode:
+		return l.lowerUnary(IROpNot, n.GetType(), node.Target)
+	case *ParenOperatorNode:
+		return l.lower(node.Target)
+	case *AndOperatorNode:
+		return l.lowerBinary(IROpAnd, n.GetType(), node.Object, node.Subject)
+	case *OrOperatorNode:
+		return l.lowerBinary(IROpOr, n.GetType(), node.Object, node.Subject)
+	case *EqualOperatorNode:
+		return l.lowerBinary(equalOps.forType(node.Object.GetType()), n.GetType(), node.Object, node.Subject)
+	case *NotEqualOperatorNode:
+		return l.lowerBinary(notEqualOps.forType(node.Object.GetType()), n.GetType(), node.Object, node.Subject)
+	case *GreaterOperatorNode:
+		return l.lowerBinary(IROpGreater, n.GetType(), node.Object, node.Subject)
+	case *GreaterOrEqualOperatorNode:
+		return l.lowerBinary(IROpGreaterOrEqual, n.GetType(), node.Object, node.Subject)
+	case *LessOperatorNode:
+		return l.lowerBinary(IROpLess, n.GetType(), node.Object, node.Subject)
+	case *LessOrEqualOperatorNode:
+		return l.lowerBinary(IROpLessOrEqual, n.GetType(), node.Object, node.Subject)
+	case *ConcatOperatorNode:
+		return l.lowerBinary(IROpConcat, n.GetType(), node.Object, node.Subject)
+	case *AddOperatorNode:
+		return l.lowerBinary(addOps.forType(n.GetType()), n.GetType(), node.Object, node.Subject)
+	case *SubOperatorNode:
+		return l.lowerBinary(subOps.forType(n.GetType()), n.GetType(), node.Object, node.Subject)
+	case *MulOperatorNode:
+		return l.lowerBinary(mulOps.forType(n.GetType()), n.GetType(), node.Object, node.Subject)
+	case *DivOperatorNode:
+		return l.lowerBinary(divOps.forType(n.GetType()), n.GetType(), node.Object, node.Subject)
+	case *ModOperatorNode:
+		return l.lowerBinary(modOps.forType(n.GetType()), n.GetType(), node.Object, node.Subject)
+	case *IsOperatorNode:
+		return l.lowerBinary(IROpIs, n.GetType(), node.Object, node.Subject)
+	case *LikeOperatorNode:
+		return l.lowerLike(node)
+	case *InOperatorNode:
+		return l.lowerIn(node)
+	case *CastOperatorNode:
+		return l.lowerCast(node)
+	case *FunctionOperatorNode:
+		return l.lowerCall(node)
+	default:
+		return 0, fmt.Errorf("ast: Lower: unsupported expression node %T", n)
+	}
+}
+
+func (l *irLowerer) lowerLike(node *LikeOperatorNode) (Register, error) {
+	obj, err := l.lower(node.Object)
+	if err != nil {
+		return 0, err
+	}
+	subj, err := l.lower(node.Subject)
+	if err != nil {
+		return 0, err
+	}
+	args := []Register{obj, subj}
+	if node.Escape != nil {
+		esc, err := l.lower(node.Escape)
+		if err != nil {
+			return 0, err
+		}
+		args = append(args, esc)
+	}
+	return l.emit(IROpLike, node.GetType(), node.Escape != nil, args...), nil
+}
+
+// inSetThreshold is the IN list length above which lowerIn switches from an
+// explicit CMP_EQ+OR disjunction chain to a BUILD_SET+IN_SET probe. A short
+// list is cheapest to compare directly; a long one amortizes the one-time
+// cost of building a set across however many probes are made against it.
+const inSetThreshold = 8
+
+// lowerIn lowers 'Left IN (Right...)'. It assumes the grammar never produces
+// an empty Right with a nil Query, the same invariant the pre-split lowerIn
+// relied on.
+func (l *irLowerer) lowerIn(node *InOperatorNode) (Register, error) {
+	if node.Query != nil {
+		return 0, fmt.Errorf("ast: Lower: IN (subquery) has no flat register form; decorrelate or rewrite to a join first")
+	}
+	left, err := l.lower(node.Left)
+	if err != nil {
+		return 0, err
+	}
+	rights := make([]Register, len(node.Right))
+	for i, r := range node.Right {
+		rr, err := l.lower(r)
+		if err != nil {
+			return 0, err
+		}
+		rights[i] = rr
+	}
+
+	boolType := ComposeDataType(DataTypeMajorBool, DataTypeMinorDontCare)
+	if len(rights) > inSetThreshold {
+		set := l.emit(IROpBuildSet, node.Left.GetType(), nil, rights...)
+		return l.emit(IROpInSet, boolType, nil, left, set), nil
+	}
+
+	eqOp := equalOps.forType(node.Left.GetType())
+	acc := l.emit(eqOp, boolType, nil, left, rights[0])
+	for _, r := range rights[1:] {
+		eq := l.emit(eqOp, boolType, nil, left, r)
+		acc = l.emit(IROpOr, boolType, nil, acc, eq)
+	}
+	return acc, nil
+}
+
+func (l *irLowerer) lowerCast(node *CastOperatorNode) (Register, error) {
+	src, err := l.lower(node.SourceExpr)
+	if err != nil {
+		return 0, err
+	}
+	return l.emit(IROpCast, node.GetType(), node.TargetType, src), nil
+}
+
+func (l *irLowerer) lowerCall(node *FunctionOperatorNode) (Register, error) {
+	args := make([]Register, 0, len(node.Args))
+	for _, a := range node.Args {
+		r, err := l.lower(a)
+		if err != nil {
+			return 0, err
+		}
+		args = append(args, r)
+	}
+	return l.emit(IROpCall, node.GetType(), node.Name.Name, args...), nil
+}
@@ -0,0 +1,94 @@
+package ast
+
+import "testing"
+
+func typedInt(v int64) *IntegerValueNode {
+	n := intLit(v)
+	n.SetType(ComposeDataType(DataTypeMajorInt, DataTypeMinor(7)))
+	return n
+}
+
+// TestLowerArithIsTypeSpecialized checks that Lower picks the Int-specialized
+// opcode for Int + Int instead of the untyped IROpAdd, so the VM gets a
+// stable opcode to dispatch on instead of re-deriving the type from every
+// instruction's Type field at run time.
+func TestLowerArithIsTypeSpecialized(t *testing.T) {
+	add := &AddOperatorNode{BinaryOperatorNode: BinaryOperatorNode{Object: typedInt(1), Subject: typedInt(2)}}
+	add.SetType(ComposeDataType(DataTypeMajorInt, DataTypeMinor(7)))
+
+	p, err := Lower(add)
+	if err != nil {
+		t.Fatalf("Lower: %v", err)
+	}
+	last := p.Instructions[len(p.Instructions)-1]
+	if last.Op != IROpAddInt {
+		t.Errorf("Op = %v, want IROpAddInt", last.Op)
+	}
+}
+
+// TestLowerEqualBytesIsTypeSpecialized checks the same for equality: two
+// FixedBytes operands must lower to IROpEqualBytes, mirroring the branch
+// Value.Equal takes at runtime for the same major.
+func TestLowerEqualBytesIsTypeSpecialized(t *testing.T) {
+	bt := ComposeDataType(DataTypeMajorFixedBytes, DataTypeMinor(31))
+	left := &BytesValueNode{}
+	left.SetType(bt)
+	right := &BytesValueNode{}
+	right.SetType(bt)
+	eq := &EqualOperatorNode{BinaryOperatorNode: BinaryOperatorNode{Object: left, Subject: right}}
+
+	p, err := Lower(eq)
+	if err != nil {
+		t.Fatalf("Lower: %v", err)
+	}
+	last := p.Instructions[len(p.Instructions)-1]
+	if last.Op != IROpEqualBytes {
+		t.Errorf("Op = %v, want IROpEqualBytes", last.Op)
+	}
+}
+
+// TestLowerInSmallListUsesEqualOrChain checks that a short IN list lowers to
+// a CMP_EQ+OR chain rather than building a set.
+func TestLowerInSmallListUsesEqualOrChain(t *testing.T) {
+	right := make([]ExprNode, 3)
+	for i := range right {
+		right[i] = typedInt(int64(i))
+	}
+	in := &InOperatorNode{Left: typedInt(0), Right: right}
+
+	p, err := Lower(in)
+	if err != nil {
+		t.Fatalf("Lower: %v", err)
+	}
+	for _, instr := range p.Instructions {
+		if instr.Op == IROpBuildSet || instr.Op == IROpInSet {
+			t.Fatalf("short IN list lowered to %v, want CMP_EQ+OR only", instr.Op)
+		}
+	}
+	last := p.Instructions[len(p.Instructions)-1]
+	if last.Op != IROpOr {
+		t.Errorf("final op = %v, want IROpOr", last.Op)
+	}
+}
+
+// TestLowerInLargeListUsesBuildSet checks that an IN list longer than
+// inSetThreshold lowers to BUILD_SET+IN_SET instead of a linear OR chain.
+func TestLowerInLargeListUsesBuildSet(t *testing.T) {
+	right := make([]ExprNode, inSetThreshold+1)
+	for i := range right {
+		right[i] = typedInt(int64(i))
+	}
+	in := &InOperatorNode{Left: typedInt(0), Right: right}
+
+	p, err := Lower(in)
+	if err != nil {
+		t.Fatalf("Lower: %v", err)
+	}
+	last := p.Instructions[len(p.Instructions)-1]
+	if last.Op != IROpInSet {
+		t.Errorf("final op = %v, want IROpInSet", last.Op)
+	}
+	if p.Instructions[len(p.Instructions)-2].Op != IROpBuildSet {
+		t.Errorf("second-to-last op = %v, want IROpBuildSet", p.Instructions[len(p.Instructions)-2].Op)
+	}
+}
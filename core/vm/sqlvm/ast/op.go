@@ -0,0 +1,571 @@
+package ast
+
+// Op identifies the concrete kind of a Node, letting callers switch on a
+// small integer instead of type-asserting every concrete node type.
+type Op int
+
+// Define valid values for Op. The zero value is invalid so a missing Op()
+// implementation is easy to spot.
+const (
+	_ Op = iota
+
+	OpIdentifier
+
+	OpBoolValue
+	OpIntegerValue
+	OpDecimalValue
+	OpBytesValue
+	OpAnyValue
+	OpDefaultValue
+	OpNullValue
+
+	OpIntType
+	OpFixedType
+	OpDynamicBytesType
+	OpFixedBytesType
+	OpAddressType
+	OpBoolType
+	OpDateType
+	OpTimeType
+	OpTimestampType
+	OpIntervalType
+
+	OpPos
+	OpNeg
+	OpNot
+	OpParen
+	OpAnd
+	OpOr
+	OpGreaterOrEqual
+	OpLessOrEqual
+	OpNotEqual
+	OpEqual
+	OpGreater
+	OpLess
+	OpConcat
+	OpAdd
+	OpSub
+	OpMul
+	OpDiv
+	OpMod
+	OpIs
+	OpLike
+
+	OpCast
+	OpAssign
+	OpIn
+	OpSubquery
+	OpExists
+	OpFunction
+
+	OpTableName
+	OpJoin
+	OpDerivedTable
+
+	OpWhere
+	OpOrder
+	OpGroup
+	OpWindow
+	OpFrame
+	OpFrameBound
+	OpOffset
+	OpLimit
+	OpInsertWithColumn
+	OpInsertWithDefault
+	OpPrimary
+	OpNotNull
+	OpUnique
+	OpAutoIncrement
+	OpDefault
+	OpForeign
+
+	OpSelectStmt
+	OpSetOpStmt
+	OpUpdateStmt
+	OpDeleteStmt
+	OpInsertStmt
+	OpCreateTableStmt
+	OpColumnSchema
+	OpCreateIndexStmt
+
+	OpAddColumnAction
+	OpDropColumnAction
+	OpRenameColumnAction
+	OpRenameTableAction
+	OpModifyColumnAction
+	OpAddConstraintAction
+	OpDropConstraintAction
+	OpAlterTableStmt
+
+	OpCreateViewStmt
+	OpDropViewStmt
+)
+
+var opStringMap = map[Op]string{
+	OpIdentifier:        "Identifier",
+	OpBoolValue:         "BoolValue",
+	OpIntegerValue:      "IntegerValue",
+	OpDecimalValue:      "DecimalValue",
+	OpBytesValue:        "BytesValue",
+	OpAnyValue:          "AnyValue",
+	OpDefaultValue:      "DefaultValue",
+	OpNullValue:         "NullValue",
+	OpIntType:           "IntType",
+	OpFixedType:         "FixedType",
+	OpDynamicBytesType:  "DynamicBytesType",
+	OpFixedBytesType:    "FixedBytesType",
+	OpAddressType:       "AddressType",
+	OpBoolType:          "BoolType",
+	OpDateType:          "DateType",
+	OpTimeType:          "TimeType",
+	OpTimestampType:     "TimestampType",
+	OpIntervalType:      "IntervalType",
+	OpPos:               "Pos",
+	OpNeg:               "Neg",
+	OpNot:               "Not",
+	OpParen:             "Paren",
+	OpAnd:               "And",
+	OpOr:                "Or",
+	OpGreaterOrEqual:    "GreaterOrEqual",
+	OpLessOrEqual:       "LessOrEqual",
+	OpNotEqual:          "NotEqual",
+	OpEqual:             "Equal",
+	OpGreater:           "Greater",
+	OpLess:              "Less",
+	OpConcat:            "Concat",
+	OpAdd:               "Add",
+	OpSub:               "Sub",
+	OpMul:               "Mul",
+	OpDiv:               "Div",
+	OpMod:               "Mod",
+	OpIs:                "Is",
+	OpLike:              "Like",
+	OpCast:              "Cast",
+	OpAssign:            "Assign",
+	OpIn:                "In",
+	OpSubquery:          "Subquery",
+	OpExists:            "Exists",
+	OpFunction:          "Function",
+	OpTableName:         "TableName",
+	OpJoin:              "Join",
+	OpDerivedTable:      "DerivedTable",
+	OpWhere:             "Where",
+	OpOrder:             "Order",
+	OpGroup:             "Group",
+	OpWindow:            "Window",
+	OpFrame:             "Frame",
+	OpFrameBound:        "FrameBound",
+	OpOffset:            "Offset",
+	OpLimit:             "Limit",
+	OpInsertWithColumn:  "InsertWithColumn",
+	OpInsertWithDefault: "InsertWithDefault",
+	OpPrimary:           "Primary",
+	OpNotNull:           "NotNull",
+	OpUnique:            "Unique",
+	OpAutoIncrement:     "AutoIncrement",
+	OpDefault:           "Default",
+	OpForeign:           "Foreign",
+	OpSelectStmt:        "SelectStmt",
+	OpSetOpStmt:         "SetOpStmt",
+	OpUpdateStmt:        "UpdateStmt",
+	OpDeleteStmt:        "DeleteStmt",
+	OpInsertStmt:        "InsertStmt",
+	OpCreateTableStmt:   "CreateTableStmt",
+	OpColumnSchema:      "ColumnSchema",
+	OpCreateIndexStmt:   "CreateIndexStmt",
+
+	OpAddColumnAction:      "AddColumnAction",
+	OpDropColumnAction:     "DropColumnAction",
+	OpRenameColumnAction:   "RenameColumnAction",
+	OpRenameTableAction:    "RenameTableAction",
+	OpModifyColumnAction:   "ModifyColumnAction",
+	OpAddConstraintAction:  "AddConstraintAction",
+	OpDropConstraintAction: "DropConstraintAction",
+	OpAlterTableStmt:       "AlterTableStmt",
+
+	OpCreateViewStmt: "CreateViewStmt",
+	OpDropViewStmt:   "DropViewStmt",
+}
+
+// String returns a human-readable name for op, or "Op(<n>)" if op is not a
+// recognized value.
+func (op Op) String() string {
+	if s, ok := opStringMap[op]; ok {
+		return s
+	}
+	return "Op(" + itoa(int(op)) + ")"
+}
+
+// itoa avoids pulling in strconv just for Op.String's fallback path.
+func itoa(n int) string {
+	if n == 0 {
+		return "0"
+	}
+	neg := n < 0
+	if neg {
+		n = -n
+	}
+	var buf [20]byte
+	i := len(buf)
+	for n > 0 {
+		i--
+		buf[i] = byte('0' + n%10)
+		n /= 10
+	}
+	if neg {
+		i--
+		buf[i] = '-'
+	}
+	return string(buf[i:])
+}
+
+// Op implementations, one per concrete node type, in the same order as the
+// Op constants above.
+
+func (n *IdentifierNode) Op() Op { return OpIdentifier }
+
+func (n *BoolValueNode) Op() Op    { return OpBoolValue }
+func (n *IntegerValueNode) Op() Op { return OpIntegerValue }
+func (n *DecimalValueNode) Op() Op { return OpDecimalValue }
+func (n *BytesValueNode) Op() Op   { return OpBytesValue }
+func (n *AnyValueNode) Op() Op     { return OpAnyValue }
+func (n *DefaultValueNode) Op() Op { return OpDefaultValue }
+func (n *NullValueNode) Op() Op    { return OpNullValue }
+
+func (n *IntTypeNode) Op() Op          { return OpIntType }
+func (n *FixedTypeNode) Op() Op        { return OpFixedType }
+func (n *DynamicBytesTypeNode) Op() Op { return OpDynamicBytesType }
+func (n *FixedBytesTypeNode) Op() Op   { return OpFixedBytesType }
+func (n *AddressTypeNode) Op() Op      { return OpAddressType }
+func (n *BoolTypeNode) Op() Op         { return OpBoolType }
+func (n *DateTypeNode) Op() Op         { return OpDateType }
+func (n *TimeTypeNode) Op() Op         { return OpTimeType }
+func (n *TimestampTypeNode) Op() Op    { return OpTimestampType }
+func (n *IntervalTypeNode) Op() Op     { return OpIntervalType }
+
+func (n *PosOperatorNode) Op() Op            { return OpPos }
+func (n *NegOperatorNode) Op() Op            { return OpNeg }
+func (n *NotOperatorNode) Op() Op            { return OpNot }
+func (n *ParenOperatorNode) Op() Op          { return OpParen }
+func (n *AndOperatorNode) Op() Op            { return OpAnd }
+func (n *OrOperatorNode) Op() Op             { return OpOr }
+func (n *GreaterOrEqualOperatorNode) Op() Op { return OpGreaterOrEqual }
+func (n *LessOrEqualOperatorNode) Op() Op    { return OpLessOrEqual }
+func (n *NotEqualOperatorNode) Op() Op       { return OpNotEqual }
+func (n *EqualOperatorNode) Op() Op          { return OpEqual }
+func (n *GreaterOperatorNode) Op() Op        { return OpGreater }
+func (n *LessOperatorNode) Op() Op           { return OpLess }
+func (n *ConcatOperatorNode) Op() Op         { return OpConcat }
+func (n *AddOperatorNode) Op() Op            { return OpAdd }
+func (n *SubOperatorNode) Op() Op            { return OpSub }
+func (n *MulOperatorNode) Op() Op            { return OpMul }
+func (n *DivOperatorNode) Op() Op            { return OpDiv }
+func (n *ModOperatorNode) Op() Op            { return OpMod }
+func (n *IsOperatorNode) Op() Op             { return OpIs }
+func (n *LikeOperatorNode) Op() Op           { return OpLike }
+
+func (n *CastOperatorNode) Op() Op     { return OpCast }
+func (n *AssignOperatorNode) Op() Op   { return OpAssign }
+func (n *InOperatorNode) Op() Op       { return OpIn }
+func (n *SubqueryOperatorNode) Op() Op { return OpSubquery }
+func (n *ExistsOperatorNode) Op() Op   { return OpExists }
+func (n *FunctionOperatorNode) Op() Op { return OpFunction }
+
+func (n *TableNameNode) Op() Op    { return OpTableName }
+func (n *JoinNode) Op() Op         { return OpJoin }
+func (n *DerivedTableNode) Op() Op { return OpDerivedTable }
+
+func (n *WhereOptionNode) Op() Op             { return OpWhere }
+func (n *OrderOptionNode) Op() Op             { return OpOrder }
+func (n *GroupOptionNode) Op() Op             { return OpGroup }
+func (n *WindowOptionNode) Op() Op            { return OpWindow }
+func (n *FrameNode) Op() Op                   { return OpFrame }
+func (n *FrameBoundNode) Op() Op              { return OpFrameBound }
+func (n *OffsetOptionNode) Op() Op            { return OpOffset }
+func (n *LimitOptionNode) Op() Op             { return OpLimit }
+func (n *InsertWithColumnOptionNode) Op() Op  { return OpInsertWithColumn }
+func (n *InsertWithDefaultOptionNode) Op() Op { return OpInsertWithDefault }
+func (n *PrimaryOptionNode) Op() Op           { return OpPrimary }
+func (n *NotNullOptionNode) Op() Op           { return OpNotNull }
+func (n *UniqueOptionNode) Op() Op            { return OpUnique }
+func (n *AutoIncrementOptionNode) Op() Op     { return OpAutoIncrement }
+func (n *DefaultOptionNode) Op() Op           { return OpDefault }
+func (n *ForeignOptionNode) Op() Op           { return OpForeign }
+
+func (n *SelectStmtNode) Op() Op      { return OpSelectStmt }
+func (n *SetOpStmtNode) Op() Op       { return OpSetOpStmt }
+func (n *UpdateStmtNode) Op() Op      { return OpUpdateStmt }
+func (n *DeleteStmtNode) Op() Op      { return OpDeleteStmt }
+func (n *InsertStmtNode) Op() Op      { return OpInsertStmt }
+func (n *CreateTableStmtNode) Op() Op { return OpCreateTableStmt }
+func (n *ColumnSchemaNode) Op() Op    { return OpColumnSchema }
+func (n *CreateIndexStmtNode) Op() Op { return OpCreateIndexStmt }
+
+func (n *AddColumnActionNode) Op() Op      { return OpAddColumnAction }
+func (n *DropColumnActionNode) Op() Op     { return OpDropColumnAction }
+func (n *RenameColumnActionNode) Op() Op   { return OpRenameColumnAction }
+func (n *RenameTableActionNode) Op() Op    { return OpRenameTableAction }
+func (n *ModifyColumnActionNode) Op() Op   { return OpModifyColumnAction }
+func (n *AddConstraintActionNode) Op() Op  { return OpAddConstraintAction }
+func (n *DropConstraintActionNode) Op() Op { return OpDropConstraintAction }
+func (n *AlterTableStmtNode) Op() Op       { return OpAlterTableStmt }
+
+func (n *CreateViewStmtNode) Op() Op { return OpCreateViewStmt }
+func (n *DropViewStmtNode) Op() Op   { return OpDropViewStmt }
+
+// OpKind classifies the structural shape of an Op. A caller that only
+// cares "is this a leaf identifier, a literal, a type name, a unary
+// operator, or a binary operator" can switch on Kind instead of
+// type-asserting every concrete node, the same motivation Op itself was
+// added for.
+type OpKind uint8
+
+// Define valid values for OpKind. The zero value is invalid, matching Op's
+// own convention of reserving the zero value for "missing".
+const (
+	_ OpKind = iota
+	KindIdentifier
+	KindValue
+	KindType
+	KindUnary
+	KindBinary
+	// KindOther covers every Op with a recognized entry in opStringMap that
+	// isn't one of the above -- statements, options, table refs, actions,
+	// and the like. Most of them need constructor arguments NewByOp can't
+	// synthesize from the Op alone, so they aren't split further here.
+	KindOther
+)
+
+var opKindMap = map[Op]OpKind{
+	OpIdentifier: KindIdentifier,
+
+	OpBoolValue:    KindValue,
+	OpIntegerValue: KindValue,
+	OpDecimalValue: KindValue,
+	OpBytesValue:   KindValue,
+	OpAnyValue:     KindValue,
+	OpDefaultValue: KindValue,
+	OpNullValue:    KindValue,
+
+	OpIntType:          KindType,
+	OpFixedType:        KindType,
+	OpDynamicBytesType: KindType,
+	OpFixedBytesType:   KindType,
+	OpAddressType:      KindType,
+	OpBoolType:         KindType,
+	OpDateType:         KindType,
+	OpTimeType:         KindType,
+	OpTimestampType:    KindType,
+	OpIntervalType:     KindType,
+
+	OpPos:   KindUnary,
+	OpNeg:   KindUnary,
+	OpNot:   KindUnary,
+	OpParen: KindUnary,
+
+	OpAnd:            KindBinary,
+	OpOr:             KindBinary,
+	OpGreaterOrEqual: KindBinary,
+	OpLessOrEqual:    KindBinary,
+	OpNotEqual:       KindBinary,
+	OpEqual:          KindBinary,
+	OpGreater:        KindBinary,
+	OpLess:           KindBinary,
+	OpConcat:         KindBinary,
+	OpAdd:            KindBinary,
+	OpSub:            KindBinary,
+	OpMul:            KindBinary,
+	OpDiv:            KindBinary,
+	OpMod:            KindBinary,
+	OpIs:             KindBinary,
+	OpLike:           KindBinary,
+}
+
+// Kind reports op's structural category: KindIdentifier, KindValue,
+// KindType, KindUnary, or KindBinary for the ops that fit one of those
+// shapes, KindOther for every other recognized Op, or the zero value if op
+// is not a recognized Op at all.
+func (op Op) Kind() OpKind {
+	if kind, ok := opKindMap[op]; ok {
+		return kind
+	}
+	if _, ok := opStringMap[op]; ok {
+		return KindOther
+	}
+	return 0
+}
+
+// NewByOp constructs a new, zero-valued node of op's concrete type. This is
+// the inverse of Op(): useful for code that only has an Op in hand (e.g. a
+// rewrite that wants "the same comparison, flipped" or a deserializer
+// reading a serialized Op tag) and needs to allocate the matching node
+// without its own type switch. The returned node's fields are left zero;
+// the caller fills them in. Returns nil if op is not a recognized Op.
+func NewByOp(op Op) Node {
+	switch op {
+	case OpIdentifier:
+		return &IdentifierNode{}
+	case OpBoolValue:
+		return &BoolValueNode{}
+	case OpIntegerValue:
+		return &IntegerValueNode{}
+	case OpDecimalValue:
+		return &DecimalValueNode{}
+	case OpBytesValue:
+		return &BytesValueNode{}
+	case OpAnyValue:
+		return &AnyValueNode{}
+	case OpDefaultValue:
+		return &DefaultValueNode{}
+	case OpNullValue:
+		return &NullValueNode{}
+	case OpIntType:
+		return &IntTypeNode{}
+	case OpFixedType:
+		return &FixedTypeNode{}
+	case OpDynamicBytesType:
+		return &DynamicBytesTypeNode{}
+	case OpFixedBytesType:
+		return &FixedBytesTypeNode{}
+	case OpAddressType:
+		return &AddressTypeNode{}
+	case OpBoolType:
+		return &BoolTypeNode{}
+	case OpDateType:
+		return &DateTypeNode{}
+	case OpTimeType:
+		return &TimeTypeNode{}
+	case OpTimestampType:
+		return &TimestampTypeNode{}
+	case OpIntervalType:
+		return &IntervalTypeNode{}
+	case OpPos:
+		return &PosOperatorNode{}
+	case OpNeg:
+		return &NegOperatorNode{}
+	case OpNot:
+		return &NotOperatorNode{}
+	case OpParen:
+		return &ParenOperatorNode{}
+	case OpAnd:
+		return &AndOperatorNode{}
+	case OpOr:
+		return &OrOperatorNode{}
+	case OpGreaterOrEqual:
+		return &GreaterOrEqualOperatorNode{}
+	case OpLessOrEqual:
+		return &LessOrEqualOperatorNode{}
+	case OpNotEqual:
+		return &NotEqualOperatorNode{}
+	case OpEqual:
+		return &EqualOperatorNode{}
+	case OpGreater:
+		return &GreaterOperatorNode{}
+	case OpLess:
+		return &LessOperatorNode{}
+	case OpConcat:
+		return &ConcatOperatorNode{}
+	case OpAdd:
+		return &AddOperatorNode{}
+	case OpSub:
+		return &SubOperatorNode{}
+	case OpMul:
+		return &MulOperatorNode{}
+	case OpDiv:
+		return &DivOperatorNode{}
+	case OpMod:
+		return &ModOperatorNode{}
+	case OpIs:
+		return &IsOperatorNode{}
+	case OpLike:
+		return &LikeOperatorNode{}
+	case OpCast:
+		return &CastOperatorNode{}
+	case OpAssign:
+		return &AssignOperatorNode{}
+	case OpIn:
+		return &InOperatorNode{}
+	case OpSubquery:
+		return &SubqueryOperatorNode{}
+	case OpExists:
+		return &ExistsOperatorNode{}
+	case OpFunction:
+		return &FunctionOperatorNode{}
+	case OpTableName:
+		return &TableNameNode{}
+	case OpJoin:
+		return &JoinNode{}
+	case OpDerivedTable:
+		return &DerivedTableNode{}
+	case OpWhere:
+		return &WhereOptionNode{}
+	case OpOrder:
+		return &OrderOptionNode{}
+	case OpGroup:
+		return &GroupOptionNode{}
+	case OpWindow:
+		return &WindowOptionNode{}
+	case OpFrame:
+		return &FrameNode{}
+	case OpFrameBound:
+		return &FrameBoundNode{}
+	case OpOffset:
+		return &OffsetOptionNode{}
+	case OpLimit:
+		return &LimitOptionNode{}
+	case OpInsertWithColumn:
+		return &InsertWithColumnOptionNode{}
+	case OpInsertWithDefault:
+		return &InsertWithDefaultOptionNode{}
+	case OpPrimary:
+		return &PrimaryOptionNode{}
+	case OpNotNull:
+		return &NotNullOptionNode{}
+	case OpUnique:
+		return &UniqueOptionNode{}
+	case OpAutoIncrement:
+		return &AutoIncrementOptionNode{}
+	case OpDefault:
+		return &DefaultOptionNode{}
+	case OpForeign:
+		return &ForeignOptionNode{}
+	case OpSelectStmt:
+		return &SelectStmtNode{}
+	case OpSetOpStmt:
+		return &SetOpStmtNode{}
+	case OpUpdateStmt:
+		return &UpdateStmtNode{}
+	case OpDeleteStmt:
+		return &DeleteStmtNode{}
+	case OpInsertStmt:
+		return &InsertStmtNode{}
+	case OpCreateTableStmt:
+		return &CreateTableStmtNode{}
+	case OpColumnSchema:
+		return &ColumnSchemaNode{}
+	case OpCreateIndexStmt:
+		return &CreateIndexStmtNode{}
+	case OpAddColumnAction:
+		return &AddColumnActionNode{}
+	case OpDropColumnAction:
+		return &DropColumnActionNode{}
+	case OpRenameColumnAction:
+		return &RenameColumnActionNode{}
+	case OpRenameTableAction:
+		return &RenameTableActionNode{}
+	case OpModifyColumnAction:
+		return &ModifyColumnActionNode{}
+	case OpAddConstraintAction:
+		return &AddConstraintActionNode{}
+	case OpDropConstraintAction:
+		return &DropConstraintActionNode{}
+	case OpAlterTableStmt:
+		return &AlterTableStmtNode{}
+	case OpCreateViewStmt:
+		return &CreateViewStmtNode{}
+	case OpDropViewStmt:
+		return &DropViewStmtNode{}
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,45 @@
+package ast
+
+import "testing"
+
+// TestOpKindClassification checks a representative sample of each OpKind
+// bucket, plus the zero value for an Op with no recognized entry at all.
+func TestOpKindClassification(t *testing.T) {
+	cases := []struct {
+		op   Op
+		want OpKind
+	}{
+		{OpIdentifier, KindIdentifier},
+		{OpIntegerValue, KindValue},
+		{OpIntType, KindType},
+		{OpNeg, KindUnary},
+		{OpAdd, KindBinary},
+		{OpSelectStmt, KindOther},
+		{Op(-1), 0},
+	}
+	for _, c := range cases {
+		if got := c.op.Kind(); got != c.want {
+			t.Errorf("Op(%v).Kind() = %v, want %v", c.op, got, c.want)
+		}
+	}
+}
+
+// TestNewByOpRoundTrips checks that NewByOp(op) returns a node whose own
+// Op() reports back op, for a representative sample spanning identifiers,
+// values, operators, and statements, and that an unrecognized Op yields nil.
+func TestNewByOpRoundTrips(t *testing.T) {
+	for _, op := range []Op{OpIdentifier, OpIntegerValue, OpAdd, OpSelectStmt, OpAlterTableStmt} {
+		n := NewByOp(op)
+		if n == nil {
+			t.Errorf("NewByOp(%v) = nil, want a node", op)
+			continue
+		}
+		if got := n.Op(); got != op {
+			t.Errorf("NewByOp(%v).Op() = %v, want %v", op, got, op)
+		}
+	}
+
+	if n := NewByOp(Op(-1)); n != nil {
+		t.Errorf("NewByOp(invalid) = %#v, want nil", n)
+	}
+}
@@ -46,6 +46,10 @@ const (
 	DataTypeMajorUint
 	DataTypeMajorFixedBytes
 	DataTypeMajorDynamicBytes
+	DataTypeMajorDate
+	DataTypeMajorTime
+	DataTypeMajorTimestamp
+	DataTypeMajorInterval
 	DataTypeMajorFixed  DataTypeMajor = 0x10
 	DataTypeMajorUfixed DataTypeMajor = 0x30
 )
@@ -58,6 +62,43 @@ const (
 	DataTypeMinorSpecialDefault DataTypeMinor = 0x02
 )
 
+// TimePrecision defines the sub-second resolution used to store a TIME,
+// TIMESTAMP, or INTERVAL value.
+type TimePrecision uint8
+
+// Define valid values for TimePrecision.
+const (
+	TimePrecisionSecond TimePrecision = iota
+	TimePrecisionMillisecond
+)
+
+// DataTypeMinor values for DataTypeMajorTime and DataTypeMajorTimestamp. Bit 0
+// selects the storage precision (0: second, 1: millisecond) and bit 1 selects
+// whether an explicit UTC offset is carried alongside the value (0: UTC only,
+// 1: with time zone).
+const (
+	DataTypeMinorTimePrecisionMask    DataTypeMinor = 0x01
+	DataTypeMinorTimeWithTimeZoneFlag DataTypeMinor = 0x02
+)
+
+// composeTemporalMinor packs the precision and time zone flag of a TIME,
+// TIMESTAMP, or INTERVAL type into a DataTypeMinor.
+func composeTemporalMinor(withTimeZone bool, precision TimePrecision) DataTypeMinor {
+	minor := DataTypeMinor(precision) & DataTypeMinorTimePrecisionMask
+	if withTimeZone {
+		minor |= DataTypeMinorTimeWithTimeZoneFlag
+	}
+	return minor
+}
+
+// decomposeTemporalMinor unpacks a DataTypeMinor produced by
+// composeTemporalMinor back into its time zone flag and precision.
+func decomposeTemporalMinor(minor DataTypeMinor) (bool, TimePrecision) {
+	withTimeZone := minor&DataTypeMinorTimeWithTimeZoneFlag != 0
+	precision := TimePrecision(minor & DataTypeMinorTimePrecisionMask)
+	return withTimeZone, precision
+}
+
 // Special data types which are commonly used.
 const (
 	DataTypePending DataType = (DataType(DataTypeMajorPending) << 8) | DataType(DataTypeMinorDontCare)
@@ -186,6 +227,13 @@ func (dt DataType) Size() uint8 {
 		return common.AddressLength
 	case DataTypeMajorInt, DataTypeMajorUint, DataTypeMajorFixedBytes:
 		return uint8(minor + 1)
+	case DataTypeMajorDate:
+		// Days since epoch fit comfortably in 4 bytes.
+		return 4
+	case DataTypeMajorTime, DataTypeMajorTimestamp, DataTypeMajorInterval:
+		// Stored as a signed offset, in the type's precision unit, from
+		// midnight (TIME), the epoch (TIMESTAMP), or zero (INTERVAL).
+		return 8
 	default:
 		panic(fmt.Sprintf("unknown data type %v", dt))
 	}
@@ -216,6 +264,17 @@ func (dt DataType) GetNode() TypeNode {
 		}
 	case DataTypeMajorDynamicBytes:
 		return &DynamicBytesTypeNode{}
+	case DataTypeMajorDate:
+		return &DateTypeNode{}
+	case DataTypeMajorTime:
+		withTimeZone, precision := decomposeTemporalMinor(minor)
+		return &TimeTypeNode{WithTimeZone: withTimeZone, Precision: precision}
+	case DataTypeMajorTimestamp:
+		withTimeZone, precision := decomposeTemporalMinor(minor)
+		return &TimestampTypeNode{WithTimeZone: withTimeZone, Precision: precision}
+	case DataTypeMajorInterval:
+		_, precision := decomposeTemporalMinor(minor)
+		return &IntervalTypeNode{Precision: precision}
 	}
 	switch {
 	case major.IsFixedRange():
@@ -290,6 +349,69 @@ var decimalMinMaxMap = func() map[DataType]decimalMinMaxPair {
 		m[dt] = decimalMinMaxPair{Min: min, Max: max}
 	}
 
+	// fixed, ufixed: byte-width loop (1..32 bytes) identical to the
+	// int/uint one above, crossed with every fractional-digit setting
+	// (0..80) since DataType packs FractionalDigits into the minor byte.
+	// DecimalEncodeChecked compares the already-Shift(minor)'d value against
+	// these bounds (see its shifted variable below), so the
+	// bounds themselves are the same plain signed/unsigned integer range as
+	// int/uint of that byte width -- the fractional digit count only
+	// changes which DataType key a given byte width's bounds are filed
+	// under, not the bounds themselves.
+	for i := uint(0); i <= 0x1f; i++ {
+		bigMax := new(big.Int)
+		bigMax.Lsh(bigIntOne, (i+1)*8-1)
+		bigMin := new(big.Int)
+		bigMin.Neg(bigMax)
+		bigMax.Sub(bigMax, bigIntOne)
+		min := decimal.NewFromBigInt(bigMin, 0)
+		max := decimal.NewFromBigInt(bigMax, 0)
+
+		uBigMax := new(big.Int)
+		uBigMax.Lsh(bigIntOne, (i+1)*8)
+		uBigMax.Sub(uBigMax, bigIntOne)
+		uMax := decimal.NewFromBigInt(uBigMax, 0)
+
+		for minor := DataTypeMinor(0); minor <= 80; minor++ {
+			dtFixed := ComposeDataType(DataTypeMajorFixed+DataTypeMajor(i), minor)
+			dtUfixed := ComposeDataType(DataTypeMajorUfixed+DataTypeMajor(i), minor)
+			m[dtFixed] = decimalMinMaxPair{Min: min, Max: max}
+			m[dtUfixed] = decimalMinMaxPair{Min: decimal.Zero, Max: uMax}
+		}
+	}
+
+	// date: signed 4-byte day offset from the epoch.
+	{
+		dt := ComposeDataType(DataTypeMajorDate, DataTypeMinorDontCare)
+		bigMax := new(big.Int)
+		bigMax.Lsh(bigIntOne, 4*8-1)
+		bigMin := new(big.Int)
+		bigMin.Neg(bigMax)
+		bigMax.Sub(bigMax, bigIntOne)
+		m[dt] = decimalMinMaxPair{
+			Min: decimal.NewFromBigInt(bigMin, 0),
+			Max: decimal.NewFromBigInt(bigMax, 0),
+		}
+	}
+
+	// time, timestamp, interval: signed 8-byte offset in the type's
+	// precision unit, for every precision/time-zone minor combination.
+	for _, major := range []DataTypeMajor{
+		DataTypeMajorTime, DataTypeMajorTimestamp, DataTypeMajorInterval,
+	} {
+		bigMax := new(big.Int)
+		bigMax.Lsh(bigIntOne, 8*8-1)
+		bigMin := new(big.Int)
+		bigMin.Neg(bigMax)
+		bigMax.Sub(bigMax, bigIntOne)
+		min := decimal.NewFromBigInt(bigMin, 0)
+		max := decimal.NewFromBigInt(bigMax, 0)
+		for minor := DataTypeMinor(0); minor <= 0x03; minor++ {
+			dt := ComposeDataType(major, minor)
+			m[dt] = decimalMinMaxPair{Min: min, Max: max}
+		}
+	}
+
 	return m
 }()
 
@@ -360,6 +482,10 @@ func DecimalEncode(dt DataType, d decimal.Decimal) ([]byte, error) {
 	case DataTypeMajorInt,
 		DataTypeMajorUint:
 		return decimalEncode(int(minor)+1, d), nil
+	case DataTypeMajorDate:
+		return decimalEncode(4, d), nil
+	case DataTypeMajorTime, DataTypeMajorTimestamp, DataTypeMajorInterval:
+		return decimalEncode(8, d), nil
 	}
 	switch {
 	case major.IsFixedRange():
@@ -375,6 +501,37 @@ func DecimalEncode(dt DataType, d decimal.Decimal) ([]byte, error) {
 	return nil, se.ErrorCodeDecimalEncode
 }
 
+// EncodeOptions controls the overflow behavior of DecimalEncodeChecked.
+type EncodeOptions struct {
+	// Strict range-checks the value against dt's bounds before packing it
+	// into bytes, returning se.ErrorCodeOverflow instead of truncating.
+	Strict bool
+}
+
+// DefaultEncodeOptions is the mode the planner uses for INSERT/UPDATE paths,
+// where surfacing an overflow beats silently corrupting a row.
+var DefaultEncodeOptions = EncodeOptions{Strict: true}
+
+// DecimalEncodeChecked behaves like DecimalEncode, but when opts.Strict is
+// set it first compares d (after any fixed/ufixed Shift) against
+// decimalMinMaxMap[dt], returning se.ErrorCodeOverflow for out-of-range
+// values rather than truncating them. Unchecked DecimalEncode remains
+// available for hot inner loops that have already range-checked upstream.
+func DecimalEncodeChecked(dt DataType, d decimal.Decimal, opts EncodeOptions) ([]byte, error) {
+	if opts.Strict {
+		major, minor := DecomposeDataType(dt)
+		shifted := d
+		if major.IsFixedRange() || major.IsUfixedRange() {
+			shifted = d.Shift(int32(minor))
+		}
+		if min, max, ok := dt.GetMinMax(); ok &&
+			(shifted.LessThan(min) || shifted.GreaterThan(max)) {
+			return nil, se.ErrorCodeOverflow
+		}
+	}
+	return DecimalEncode(dt, d)
+}
+
 // DecimalDecode decodes decimal from bytes.
 func DecimalDecode(dt DataType, b []byte) (decimal.Decimal, error) {
 	major, minor := DecomposeDataType(dt)
@@ -383,6 +540,9 @@ func DecimalDecode(dt DataType, b []byte) (decimal.Decimal, error) {
 		return decimalDecode(true, b), nil
 	case DataTypeMajorUint:
 		return decimalDecode(false, b), nil
+	case DataTypeMajorDate, DataTypeMajorTime, DataTypeMajorTimestamp,
+		DataTypeMajorInterval:
+		return decimalDecode(true, b), nil
 	}
 	switch {
 	case major.IsFixedRange():
@@ -394,6 +554,25 @@ func DecimalDecode(dt DataType, b []byte) (decimal.Decimal, error) {
 	return decimal.Zero, se.ErrorCodeDecimalDecode
 }
 
+// DecimalDecodeChecked behaves like DecimalDecode, but for fixed/ufixed
+// types additionally asserts the result's exponent is exactly the negated
+// fractional digit count of dt, returning se.ErrorCodeOverflow otherwise.
+// DecimalDecode always shifts by that count, so for well-formed input the
+// assertion holds; the bytes themselves are not otherwise validated.
+func DecimalDecodeChecked(dt DataType, b []byte) (decimal.Decimal, error) {
+	d, err := DecimalDecode(dt, b)
+	if err != nil {
+		return d, err
+	}
+	if major, minor := DecomposeDataType(dt); major.IsFixedRange() ||
+		major.IsUfixedRange() {
+		if -d.Exponent() != int32(minor) {
+			return decimal.Zero, se.ErrorCodeOverflow
+		}
+	}
+	return d, nil
+}
+
 // DecimalToUint64 convert decimal to uint64.
 // Negative case will return error, and decimal part will be trancated.
 func DecimalToUint64(d decimal.Decimal) (uint64, error) {
@@ -0,0 +1,94 @@
+package ast
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/dexon-foundation/decimal"
+
+	se "github.com/dexon-foundation/dexon/core/vm/sqlvm/errors"
+)
+
+// checkEncodeBoundary asserts that the pre-shift decimal whose Shift(minor)
+// lands exactly on shiftedMin/shiftedMax round-trips through
+// DecimalEncodeChecked, and that nudging it one unit further in either
+// direction is rejected with se.ErrorCodeOverflow instead of panicking or
+// silently truncating.
+func checkEncodeBoundary(t *testing.T, dt DataType, minor int32, shiftedMin, shiftedMax decimal.Decimal) {
+	t.Helper()
+
+	min := shiftedMin.Shift(-minor)
+	max := shiftedMax.Shift(-minor)
+	unit := decimal.New(1, -minor)
+
+	if _, err := DecimalEncodeChecked(dt, min, DefaultEncodeOptions); err != nil {
+		t.Errorf("dt=%#04x minor=%d: Min %s rejected: %v", dt, minor, min, err)
+	}
+	if _, err := DecimalEncodeChecked(dt, max, DefaultEncodeOptions); err != nil {
+		t.Errorf("dt=%#04x minor=%d: Max %s rejected: %v", dt, minor, max, err)
+	}
+	if _, err := DecimalEncodeChecked(dt, min.Sub(unit), DefaultEncodeOptions); err != se.ErrorCodeOverflow {
+		t.Errorf("dt=%#04x minor=%d: below Min: got err=%v, want ErrorCodeOverflow", dt, minor, err)
+	}
+	if _, err := DecimalEncodeChecked(dt, max.Add(unit), DefaultEncodeOptions); err != se.ErrorCodeOverflow {
+		t.Errorf("dt=%#04x minor=%d: above Max: got err=%v, want ErrorCodeOverflow", dt, minor, err)
+	}
+}
+
+// TestDecimalEncodeCheckedIntUint exhaustively walks all 32 int/uint byte
+// widths, checking that the overflow-checked encoder accepts each width's
+// exact bounds and rejects one unit past them.
+func TestDecimalEncodeCheckedIntUint(t *testing.T) {
+	for i := uint(0); i <= 0x1f; i++ {
+		size := (i + 1) * 8
+		bigMax := new(big.Int).Lsh(bigIntOne, size-1)
+		bigMin := new(big.Int).Neg(bigMax)
+		bigMax.Sub(bigMax, bigIntOne)
+		min := decimal.NewFromBigInt(bigMin, 0)
+		max := decimal.NewFromBigInt(bigMax, 0)
+
+		uBigMax := new(big.Int).Lsh(bigIntOne, size)
+		uBigMax.Sub(uBigMax, bigIntOne)
+		uMax := decimal.NewFromBigInt(uBigMax, 0)
+
+		dtInt := ComposeDataType(DataTypeMajorInt, DataTypeMinor(i))
+		dtUint := ComposeDataType(DataTypeMajorUint, DataTypeMinor(i))
+		checkEncodeBoundary(t, dtInt, 0, min, max)
+		checkEncodeBoundary(t, dtUint, 0, decimal.Zero, uMax)
+	}
+}
+
+// TestDecimalEncodeCheckedFixedUfixed exhaustively walks every (byte-width,
+// fractional-digit) combination for DataTypeMajorFixed and
+// DataTypeMajorUfixed, the family chunk0-2 was explicitly about.
+func TestDecimalEncodeCheckedFixedUfixed(t *testing.T) {
+	for i := uint(0); i <= 0x1f; i++ {
+		size := (i + 1) * 8
+		bigMax := new(big.Int).Lsh(bigIntOne, size-1)
+		bigMin := new(big.Int).Neg(bigMax)
+		bigMax.Sub(bigMax, bigIntOne)
+		min := decimal.NewFromBigInt(bigMin, 0)
+		max := decimal.NewFromBigInt(bigMax, 0)
+
+		uBigMax := new(big.Int).Lsh(bigIntOne, size)
+		uBigMax.Sub(uBigMax, bigIntOne)
+		uMax := decimal.NewFromBigInt(uBigMax, 0)
+
+		for minor := uint(0); minor <= 80; minor++ {
+			dtFixed := ComposeDataType(DataTypeMajorFixed+DataTypeMajor(i), DataTypeMinor(minor))
+			dtUfixed := ComposeDataType(DataTypeMajorUfixed+DataTypeMajor(i), DataTypeMinor(minor))
+			checkEncodeBoundary(t, dtFixed, int32(minor), min, max)
+			checkEncodeBoundary(t, dtUfixed, int32(minor), decimal.Zero, uMax)
+		}
+	}
+}
+
+// TestDecimalEncodeCheckedRelaxed confirms that a relaxed (non-Strict) call
+// skips the range check entirely and falls back to raw DecimalEncode,
+// matching the documented opt-in nature of Strict.
+func TestDecimalEncodeCheckedRelaxed(t *testing.T) {
+	dt := ComposeDataType(DataTypeMajorUint, DataTypeMinor(0))
+	if _, err := DecimalEncodeChecked(dt, decimal.New(-5, 0), EncodeOptions{Strict: false}); err != nil {
+		t.Errorf("relaxed encode of an out-of-range value should not error, got: %v", err)
+	}
+}
@@ -0,0 +1,902 @@
+package ast
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Format renders n back into canonical SQL text. It is meant to round-trip
+// a parsed statement for debugging, logging, query EXPLAIN output,
+// migration diffs, and tests that build an AST programmatically and want to
+// compare it against golden SQL -- not to reproduce the user's original
+// formatting or whitespace. Parenthesization is driven by the opPrecedence,
+// opRightAssocNeedsParens, and opRoundingSensitive tables (see
+// unparseOperand), so a tree with ParenOperatorNode stripped out still
+// round-trips to the same semantics.
+func Format(n Node) ([]byte, error) {
+	var b strings.Builder
+	if err := unparse(&b, n); err != nil {
+		return nil, err
+	}
+	return []byte(b.String()), nil
+}
+
+// FormatTo is Format, writing directly to w instead of returning a []byte.
+func FormatTo(w io.Writer, n Node) error {
+	var b strings.Builder
+	if err := unparse(&b, n); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func unparse(b *strings.Builder, n Node) error {
+	switch node := n.(type) {
+	case *IdentifierNode:
+		if node.Qualifier != nil {
+			if err := unparse(b, node.Qualifier); err != nil {
+				return err
+			}
+			b.WriteByte('.')
+		}
+		writeIdentifier(b, node.Name)
+	case *BoolValueNode:
+		b.WriteString(strings.ToUpper(strconv.FormatBool(node.V)))
+	case *IntegerValueNode:
+		b.WriteString(node.V.String())
+	case *DecimalValueNode:
+		b.WriteString(node.V.String())
+	case *BytesValueNode:
+		writeBytesLiteral(b, node.V)
+	case *AnyValueNode:
+		b.WriteByte('*')
+	case *DefaultValueNode:
+		b.WriteString("DEFAULT")
+	case *NullValueNode:
+		b.WriteString("NULL")
+	case *IntTypeNode:
+		name := "int"
+		if node.Unsigned {
+			name = "uint"
+		}
+		fmt.Fprintf(b, "%s%d", name, node.Size)
+	case *FixedTypeNode:
+		name := "fixed"
+		if node.Unsigned {
+			name = "ufixed"
+		}
+		fmt.Fprintf(b, "%s%dx%d", name, node.Size, node.FractionalDigits)
+	case *DynamicBytesTypeNode:
+		b.WriteString("bytes")
+	case *FixedBytesTypeNode:
+		fmt.Fprintf(b, "bytes%d", node.Size)
+	case *AddressTypeNode:
+		b.WriteString("address")
+	case *BoolTypeNode:
+		b.WriteString("bool")
+	case *DateTypeNode:
+		b.WriteString("date")
+	case *TimeTypeNode:
+		writeTemporalTypeName(b, "time", node.WithTimeZone, node.Precision)
+	case *TimestampTypeNode:
+		writeTemporalTypeName(b, "timestamp", node.WithTimeZone, node.Precision)
+	case *IntervalTypeNode:
+		b.WriteString("interval")
+		if node.Precision == TimePrecisionMillisecond {
+			b.WriteString("(3)")
+		}
+	case *PosOperatorNode:
+		return unparseUnary(b, OpPos, "+", node.Target)
+	case *NegOperatorNode:
+		return unparseUnary(b, OpNeg, "-", node.Target)
+	case *NotOperatorNode:
+		return unparseUnary(b, OpNot, "NOT ", node.Target)
+	case *ParenOperatorNode:
+		b.WriteByte('(')
+		if err := unparse(b, node.Target); err != nil {
+			return err
+		}
+		b.WriteByte(')')
+	case *AndOperatorNode:
+		return unparseBinary(b, OpAnd, node.Object, " AND ", node.Subject)
+	case *OrOperatorNode:
+		return unparseBinary(b, OpOr, node.Object, " OR ", node.Subject)
+	case *GreaterOrEqualOperatorNode:
+		return unparseBinary(b, OpGreaterOrEqual, node.Object, " >= ", node.Subject)
+	case *LessOrEqualOperatorNode:
+		return unparseBinary(b, OpLessOrEqual, node.Object, " <= ", node.Subject)
+	case *NotEqualOperatorNode:
+		return unparseBinary(b, OpNotEqual, node.Object, " <> ", node.Subject)
+	case *EqualOperatorNode:
+		return unparseBinary(b, OpEqual, node.Object, " = ", node.Subject)
+	case *GreaterOperatorNode:
+		return unparseBinary(b, OpGreater, node.Object, " > ", node.Subject)
+	case *LessOperatorNode:
+		return unparseBinary(b, OpLess, node.Object, " < ", node.Subject)
+	case *ConcatOperatorNode:
+		return unparseBinary(b, OpConcat, node.Object, " || ", node.Subject)
+	case *AddOperatorNode:
+		return unparseBinary(b, OpAdd, node.Object, " + ", node.Subject)
+	case *SubOperatorNode:
+		return unparseBinary(b, OpSub, node.Object, " - ", node.Subject)
+	case *MulOperatorNode:
+		return unparseBinary(b, OpMul, node.Object, " * ", node.Subject)
+	case *DivOperatorNode:
+		return unparseBinary(b, OpDiv, node.Object, " / ", node.Subject)
+	case *ModOperatorNode:
+		return unparseBinary(b, OpMod, node.Object, " % ", node.Subject)
+	case *IsOperatorNode:
+		return unparseBinary(b, OpIs, node.Object, " IS ", node.Subject)
+	case *LikeOperatorNode:
+		if err := unparseBinary(b, OpLike, node.Object, " LIKE ", node.Subject); err != nil {
+			return err
+		}
+		if node.Escape != nil {
+			b.WriteString(" ESCAPE ")
+			if err := unparse(b, node.Escape); err != nil {
+				return err
+			}
+		}
+	case *CastOperatorNode:
+		b.WriteString("CAST(")
+		if err := unparse(b, node.SourceExpr); err != nil {
+			return err
+		}
+		b.WriteString(" AS ")
+		if err := unparse(b, node.TargetType); err != nil {
+			return err
+		}
+		b.WriteByte(')')
+	case *AssignOperatorNode:
+		if err := unparse(b, node.Column); err != nil {
+			return err
+		}
+		b.WriteString(" = ")
+		return unparse(b, node.Expr)
+	case *InOperatorNode:
+		if err := unparseOperand(b, OpIn, node.Left, false); err != nil {
+			return err
+		}
+		b.WriteString(" IN (")
+		if node.Query != nil {
+			if err := unparse(b, node.Query); err != nil {
+				return err
+			}
+		} else if err := unparseExprList(b, node.Right); err != nil {
+			return err
+		}
+		b.WriteByte(')')
+	case *SubqueryOperatorNode:
+		b.WriteByte('(')
+		if err := unparse(b, node.Query); err != nil {
+			return err
+		}
+		b.WriteByte(')')
+	case *ExistsOperatorNode:
+		b.WriteString("EXISTS (")
+		if err := unparse(b, node.Query); err != nil {
+			return err
+		}
+		b.WriteByte(')')
+	case *FunctionOperatorNode:
+		if err := unparse(b, node.Name); err != nil {
+			return err
+		}
+		b.WriteByte('(')
+		if err := unparseExprList(b, node.Args); err != nil {
+			return err
+		}
+		b.WriteByte(')')
+		if node.Over != nil {
+			b.WriteString(" OVER ")
+			if err := unparse(b, node.Over); err != nil {
+				return err
+			}
+		}
+	case *WindowOptionNode:
+		return unparseWindow(b, node)
+	case *FrameNode:
+		return unparseFrame(b, node)
+	case *FrameBoundNode:
+		return unparseFrameBound(b, node)
+	case *TableNameNode:
+		if err := unparse(b, node.Table); err != nil {
+			return err
+		}
+		if node.Alias != nil {
+			b.WriteString(" AS ")
+			if err := unparse(b, node.Alias); err != nil {
+				return err
+			}
+		}
+	case *JoinNode:
+		return unparseJoin(b, node)
+	case *DerivedTableNode:
+		b.WriteByte('(')
+		if err := unparse(b, node.Query); err != nil {
+			return err
+		}
+		b.WriteByte(')')
+		if node.Alias != nil {
+			b.WriteString(" AS ")
+			if err := unparse(b, node.Alias); err != nil {
+				return err
+			}
+		}
+	case *WhereOptionNode:
+		b.WriteString("WHERE ")
+		return unparse(b, node.Condition)
+	case *OrderOptionNode:
+		if err := unparse(b, node.Expr); err != nil {
+			return err
+		}
+		if node.Desc {
+			b.WriteString(" DESC")
+		} else {
+			b.WriteString(" ASC")
+		}
+		if node.NullsFirst {
+			b.WriteString(" NULLS FIRST")
+		} else {
+			b.WriteString(" NULLS LAST")
+		}
+	case *GroupOptionNode:
+		return unparse(b, node.Expr)
+	case *OffsetOptionNode:
+		b.WriteString("OFFSET ")
+		return unparse(b, node.Value)
+	case *LimitOptionNode:
+		b.WriteString("LIMIT ")
+		return unparse(b, node.Value)
+	case *InsertWithDefaultOptionNode:
+		b.WriteString("DEFAULT VALUES")
+	case *PrimaryOptionNode:
+		b.WriteString("PRIMARY KEY")
+	case *NotNullOptionNode:
+		b.WriteString("NOT NULL")
+	case *UniqueOptionNode:
+		b.WriteString("UNIQUE")
+	case *AutoIncrementOptionNode:
+		b.WriteString("AUTOINCREMENT")
+	case *DefaultOptionNode:
+		b.WriteString("DEFAULT ")
+		return unparse(b, node.Value)
+	case *ForeignOptionNode:
+		b.WriteString("REFERENCES ")
+		if err := unparse(b, node.Table); err != nil {
+			return err
+		}
+		b.WriteByte('(')
+		if err := unparse(b, node.Column); err != nil {
+			return err
+		}
+		b.WriteByte(')')
+	case *InsertWithColumnOptionNode:
+		if len(node.Column) > 0 {
+			b.WriteByte('(')
+			for i, c := range node.Column {
+				if i > 0 {
+					b.WriteString(", ")
+				}
+				if err := unparse(b, c); err != nil {
+					return err
+				}
+			}
+			b.WriteByte(')')
+			b.WriteString(" VALUES ")
+		} else {
+			b.WriteString("VALUES ")
+		}
+		for i, row := range node.Value {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			b.WriteByte('(')
+			if err := unparseExprList(b, row); err != nil {
+				return err
+			}
+			b.WriteByte(')')
+		}
+	case *SelectStmtNode:
+		return unparseSelect(b, node)
+	case *SetOpStmtNode:
+		return unparseSetOp(b, node)
+	case *UpdateStmtNode:
+		return unparseUpdate(b, node)
+	case *DeleteStmtNode:
+		return unparseDelete(b, node)
+	case *InsertStmtNode:
+		return unparseInsert(b, node)
+	case *CreateTableStmtNode:
+		return unparseCreateTable(b, node)
+	case *ColumnSchemaNode:
+		return unparseColumnSchema(b, node)
+	case *CreateIndexStmtNode:
+		return unparseCreateIndex(b, node)
+	case *AddColumnActionNode:
+		b.WriteString("ADD COLUMN ")
+		return unparseColumnSchema(b, node.Column)
+	case *DropColumnActionNode:
+		b.WriteString("DROP COLUMN ")
+		return unparse(b, node.Column)
+	case *RenameColumnActionNode:
+		b.WriteString("RENAME COLUMN ")
+		if err := unparse(b, node.From); err != nil {
+			return err
+		}
+		b.WriteString(" TO ")
+		return unparse(b, node.To)
+	case *RenameTableActionNode:
+		b.WriteString("RENAME TO ")
+		return unparse(b, node.To)
+	case *ModifyColumnActionNode:
+		b.WriteString("MODIFY COLUMN ")
+		if err := unparse(b, node.Column); err != nil {
+			return err
+		}
+		b.WriteByte(' ')
+		return unparse(b, node.DataType)
+	case *AddConstraintActionNode:
+		b.WriteString("ADD CONSTRAINT ")
+		if err := unparse(b, node.Name); err != nil {
+			return err
+		}
+		b.WriteByte(' ')
+		return unparse(b, node.Constraint)
+	case *DropConstraintActionNode:
+		b.WriteString("DROP CONSTRAINT ")
+		return unparse(b, node.Name)
+	case *AlterTableStmtNode:
+		b.WriteString("ALTER TABLE ")
+		if err := unparse(b, node.Table); err != nil {
+			return err
+		}
+		b.WriteByte(' ')
+		for i, action := range node.Actions {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			if err := unparse(b, action); err != nil {
+				return err
+			}
+		}
+		return nil
+	case *CreateViewStmtNode:
+		b.WriteString("CREATE VIEW ")
+		if err := unparse(b, node.View); err != nil {
+			return err
+		}
+		b.WriteString(" AS ")
+		return unparse(b, node.Query)
+	case *DropViewStmtNode:
+		b.WriteString("DROP VIEW ")
+		return unparse(b, node.View)
+	default:
+		return fmt.Errorf("ast: Unparse: unsupported node type %T", n)
+	}
+	return nil
+}
+
+func writeIdentifier(b *strings.Builder, name []byte) {
+	b.WriteByte('"')
+	for _, c := range name {
+		if c == '"' {
+			b.WriteByte('"')
+		}
+		b.WriteByte(c)
+	}
+	b.WriteByte('"')
+}
+
+func writeBytesLiteral(b *strings.Builder, v []byte) {
+	b.WriteString("0x")
+	const hex = "0123456789abcdef"
+	for _, c := range v {
+		b.WriteByte(hex[c>>4])
+		b.WriteByte(hex[c&0x0f])
+	}
+}
+
+func writeTemporalTypeName(b *strings.Builder, base string, withTimeZone bool, precision TimePrecision) {
+	b.WriteString(base)
+	if precision == TimePrecisionMillisecond {
+		b.WriteString("(3)")
+	}
+	if withTimeZone {
+		b.WriteString(" with time zone")
+	}
+}
+
+// opPrecedence ranks each binary/unary operator Op by how tightly it binds,
+// higher binding tighter, mirroring standard SQL operator precedence. Op
+// values with no entry (literals, function calls, CAST, ParenOperatorNode,
+// ...) are already fully delimited by their own syntax and never need
+// parenthesizing as someone else's operand.
+var opPrecedence = map[Op]int{
+	OpOr: 1,
+
+	OpAnd: 2,
+
+	OpNot: 3,
+
+	OpEqual:          4,
+	OpNotEqual:       4,
+	OpGreater:        4,
+	OpGreaterOrEqual: 4,
+	OpLess:           4,
+	OpLessOrEqual:    4,
+	OpIs:             4,
+	OpLike:           4,
+	OpIn:             4,
+
+	OpConcat: 5,
+
+	OpAdd: 6,
+	OpSub: 6,
+
+	OpMul: 7,
+	OpDiv: 7,
+	OpMod: 7,
+
+	OpPos: 8,
+	OpNeg: 8,
+}
+
+// opRightAssocNeedsParens lists the binary operators for which "a op b op c"
+// does NOT reassociate as "a op (b op c)" -- subtraction, division, modulo,
+// and the comparison operators (=, <>, <, <=, >, >=, IS, LIKE) -- so a
+// same-precedence operator standing as the right operand
+// must keep its parentheses. The commutative/associative OR, AND, and
+// CONCAT groups are left out because right-nesting them at equal precedence
+// never changes the result. Mul is deliberately not listed here even though
+// "a * (b * c)" is likewise safe to flatten -- see opRoundingSensitive for
+// why Mul still needs special-casing against a Div/Mod child.
+var opRightAssocNeedsParens = map[Op]bool{
+	OpSub: true,
+	OpDiv: true,
+	OpMod: true,
+
+	OpEqual:          true,
+	OpNotEqual:       true,
+	OpGreater:        true,
+	OpGreaterOrEqual: true,
+	OpLess:           true,
+	OpLessOrEqual:    true,
+	OpIs:             true,
+	OpLike:           true,
+}
+
+// opRoundingSensitive marks operators whose decimal result is only exact up
+// to DivisionPrecision places. Real-number algebra says "a * (b / c)" and
+// "(a * b) / c" are the same value, so precedence alone would let Div drop
+// its parentheses as a child of Mul; but decimal division rounds where
+// multiplication doesn't, so the two groupings can round to different
+// results. Any Div/Mod standing inside a Mul/Div/Mod parent must therefore
+// keep its parentheses regardless of precedence or operand side.
+var opRoundingSensitive = map[Op]bool{
+	OpDiv: true,
+	OpMod: true,
+}
+
+var opMultiplicativeTier = map[Op]bool{
+	OpMul: true,
+	OpDiv: true,
+	OpMod: true,
+}
+
+// unparseOperand writes child as an operand of the binary/unary operator
+// parent, wrapping it in parentheses exactly when omitting them would let
+// it reassociate into a different expression: when child binds more
+// loosely than parent, binds equally but sits on the right of a
+// non-associative parent, or crosses a decimal-rounding boundary that
+// precedence alone can't see (opRoundingSensitive).
+func unparseOperand(b *strings.Builder, parent Op, child ExprNode, isRightOperand bool) error {
+	childOp := child.Op()
+	childPrec, childHasPrec := opPrecedence[childOp]
+	parentPrec := opPrecedence[parent]
+	needParens := childHasPrec && (childPrec < parentPrec ||
+		(childPrec == parentPrec && isRightOperand && opRightAssocNeedsParens[parent]))
+	if !needParens && opRoundingSensitive[childOp] && opMultiplicativeTier[parent] {
+		needParens = true
+	}
+	if !needParens {
+		return unparse(b, child)
+	}
+	b.WriteByte('(')
+	if err := unparse(b, child); err != nil {
+		return err
+	}
+	b.WriteByte(')')
+	return nil
+}
+
+func unparseUnary(b *strings.Builder, op Op, opStr string, target ExprNode) error {
+	b.WriteString(opStr)
+	return unparseOperand(b, op, target, false)
+}
+
+func unparseBinary(b *strings.Builder, op Op, object ExprNode, opStr string, subject ExprNode) error {
+	if err := unparseOperand(b, op, object, false); err != nil {
+		return err
+	}
+	b.WriteString(opStr)
+	return unparseOperand(b, op, subject, true)
+}
+
+func unparseExprList(b *strings.Builder, exprs []ExprNode) error {
+	for i, e := range exprs {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		if err := unparse(b, e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func unparseWindow(b *strings.Builder, node *WindowOptionNode) error {
+	b.WriteByte('(')
+	wroteClause := false
+	if len(node.PartitionBy) > 0 {
+		b.WriteString("PARTITION BY ")
+		if err := unparseExprList(b, node.PartitionBy); err != nil {
+			return err
+		}
+		wroteClause = true
+	}
+	if len(node.OrderBy) > 0 {
+		if wroteClause {
+			b.WriteByte(' ')
+		}
+		b.WriteString("ORDER BY ")
+		for i, o := range node.OrderBy {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			if err := unparse(b, o); err != nil {
+				return err
+			}
+		}
+		wroteClause = true
+	}
+	if node.Frame != nil {
+		if wroteClause {
+			b.WriteByte(' ')
+		}
+		if err := unparseFrame(b, node.Frame); err != nil {
+			return err
+		}
+	}
+	b.WriteByte(')')
+	return nil
+}
+
+var frameUnitKeyword = map[FrameUnit]string{
+	FrameUnitRows:  "ROWS",
+	FrameUnitRange: "RANGE",
+}
+
+func unparseFrame(b *strings.Builder, node *FrameNode) error {
+	b.WriteString(frameUnitKeyword[node.Unit])
+	b.WriteString(" BETWEEN ")
+	if err := unparseFrameBound(b, node.Start); err != nil {
+		return err
+	}
+	b.WriteString(" AND ")
+	return unparseFrameBound(b, node.End)
+}
+
+func unparseFrameBound(b *strings.Builder, node *FrameBoundNode) error {
+	switch node.Kind {
+	case FrameBoundUnboundedPreceding:
+		b.WriteString("UNBOUNDED PRECEDING")
+	case FrameBoundPreceding:
+		if err := unparse(b, node.Offset); err != nil {
+			return err
+		}
+		b.WriteString(" PRECEDING")
+	case FrameBoundCurrentRow:
+		b.WriteString("CURRENT ROW")
+	case FrameBoundFollowing:
+		if err := unparse(b, node.Offset); err != nil {
+			return err
+		}
+		b.WriteString(" FOLLOWING")
+	case FrameBoundUnboundedFollowing:
+		b.WriteString("UNBOUNDED FOLLOWING")
+	}
+	return nil
+}
+
+var joinTypeKeyword = map[JoinType]string{
+	JoinTypeInner: "JOIN",
+	JoinTypeLeft:  "LEFT JOIN",
+	JoinTypeRight: "RIGHT JOIN",
+	JoinTypeFull:  "FULL JOIN",
+	JoinTypeCross: "CROSS JOIN",
+}
+
+func unparseJoin(b *strings.Builder, node *JoinNode) error {
+	if err := unparse(b, node.Left); err != nil {
+		return err
+	}
+	b.WriteByte(' ')
+	b.WriteString(joinTypeKeyword[node.Type])
+	b.WriteByte(' ')
+	if err := unparse(b, node.Right); err != nil {
+		return err
+	}
+	if node.On != nil {
+		b.WriteString(" ON ")
+		if err := unparse(b, node.On); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func unparseSelect(b *strings.Builder, node *SelectStmtNode) error {
+	b.WriteString("SELECT ")
+	if err := unparseExprList(b, node.Column); err != nil {
+		return err
+	}
+	if node.Table != nil {
+		b.WriteString(" FROM ")
+		if err := unparse(b, node.Table); err != nil {
+			return err
+		}
+	}
+	if node.Where != nil {
+		b.WriteByte(' ')
+		if err := unparse(b, node.Where); err != nil {
+			return err
+		}
+	}
+	if len(node.Group) > 0 {
+		b.WriteString(" GROUP BY ")
+		for i, g := range node.Group {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			if err := unparse(b, g); err != nil {
+				return err
+			}
+		}
+	}
+	if len(node.Order) > 0 {
+		b.WriteString(" ORDER BY ")
+		for i, o := range node.Order {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			if err := unparse(b, o); err != nil {
+				return err
+			}
+		}
+	}
+	if node.Limit != nil {
+		b.WriteByte(' ')
+		if err := unparse(b, node.Limit); err != nil {
+			return err
+		}
+	}
+	if node.Offset != nil {
+		b.WriteByte(' ')
+		if err := unparse(b, node.Offset); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var setOpKeyword = map[SetOpType]string{
+	SetOpUnion:     "UNION",
+	SetOpIntersect: "INTERSECT",
+	SetOpExcept:    "EXCEPT",
+}
+
+func unparseSetOp(b *strings.Builder, node *SetOpStmtNode) error {
+	if err := unparse(b, node.Left); err != nil {
+		return err
+	}
+	b.WriteByte(' ')
+	b.WriteString(setOpKeyword[node.Type])
+	if node.All {
+		b.WriteString(" ALL")
+	}
+	b.WriteByte(' ')
+	return unparse(b, node.Right)
+}
+
+func unparseUpdate(b *strings.Builder, node *UpdateStmtNode) error {
+	b.WriteString("UPDATE ")
+	if err := unparse(b, node.Table); err != nil {
+		return err
+	}
+	b.WriteString(" SET ")
+	for i, a := range node.Assignment {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		if err := unparse(b, a); err != nil {
+			return err
+		}
+	}
+	if node.Where != nil {
+		b.WriteByte(' ')
+		if err := unparse(b, node.Where); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func unparseDelete(b *strings.Builder, node *DeleteStmtNode) error {
+	b.WriteString("DELETE FROM ")
+	if err := unparse(b, node.Table); err != nil {
+		return err
+	}
+	if node.Where != nil {
+		b.WriteByte(' ')
+		return unparse(b, node.Where)
+	}
+	return nil
+}
+
+func unparseInsert(b *strings.Builder, node *InsertStmtNode) error {
+	b.WriteString("INSERT INTO ")
+	if err := unparse(b, node.Table); err != nil {
+		return err
+	}
+	b.WriteByte(' ')
+	return unparse(b, node.Insert)
+}
+
+func unparseCreateTable(b *strings.Builder, node *CreateTableStmtNode) error {
+	b.WriteString("CREATE TABLE ")
+	if err := unparse(b, node.Table); err != nil {
+		return err
+	}
+	b.WriteString(" (")
+	for i, c := range node.Column {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		if err := unparse(b, c); err != nil {
+			return err
+		}
+	}
+	b.WriteByte(')')
+	return nil
+}
+
+func unparseColumnSchema(b *strings.Builder, node *ColumnSchemaNode) error {
+	if err := unparse(b, node.Column); err != nil {
+		return err
+	}
+	b.WriteByte(' ')
+	if err := unparse(b, node.DataType); err != nil {
+		return err
+	}
+	for _, c := range node.Constraint {
+		b.WriteByte(' ')
+		if err := unparse(b, c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func unparseCreateIndex(b *strings.Builder, node *CreateIndexStmtNode) error {
+	b.WriteString("CREATE ")
+	if node.Unique != nil {
+		b.WriteString("UNIQUE ")
+	}
+	b.WriteString("INDEX ")
+	if err := unparse(b, node.Index); err != nil {
+		return err
+	}
+	b.WriteString(" ON ")
+	if err := unparse(b, node.Table); err != nil {
+		return err
+	}
+	b.WriteString(" (")
+	for i, c := range node.Column {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		if err := unparse(b, c); err != nil {
+			return err
+		}
+	}
+	b.WriteByte(')')
+	return nil
+}
+
+// Dump renders n's tree structure for debugging, one exported field per
+// line with nested nodes indented underneath -- similar in spirit to
+// go/ast.Fprint. Fields tagged `print:"-"` (NodeBase.Position/Length,
+// TaggedExprNodeBase.Type, StmtNodeBase.Verb) are bookkeeping, not tree
+// content, and are omitted here; rewriteReflect in visitor.go, by contrast,
+// still visits such fields.
+func Dump(n Node) string {
+	var b strings.Builder
+	dumpNode(&b, reflect.ValueOf(n), 0)
+	return b.String()
+}
+
+func dumpIndent(b *strings.Builder, depth int) {
+	for i := 0; i < depth; i++ {
+		b.WriteString("    ")
+	}
+}
+
+// dumpNode writes the struct literal backing v, a Node-typed reflect.Value,
+// recursing into its exported fields via dumpFields.
+func dumpNode(b *strings.Builder, v reflect.Value, depth int) {
+	if v.Kind() == reflect.Ptr && v.IsNil() {
+		b.WriteString("nil")
+		return
+	}
+	elem := v.Elem()
+	fmt.Fprintf(b, "%s {\n", elem.Type().Name())
+	dumpFields(b, elem, depth+1)
+	dumpIndent(b, depth)
+	b.WriteByte('}')
+}
+
+func dumpFields(b *strings.Builder, v reflect.Value, depth int) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue // unexported
+		}
+		if field.Tag.Get("print") == "-" {
+			continue // hidden bookkeeping field, not tree content
+		}
+		fv := v.Field(i)
+		if field.Anonymous && fv.Kind() == reflect.Struct {
+			dumpFields(b, fv, depth)
+			continue
+		}
+		dumpIndent(b, depth)
+		fmt.Fprintf(b, "%s: ", field.Name)
+		dumpValue(b, fv, depth)
+		b.WriteByte('\n')
+	}
+}
+
+// dumpValue writes a single field value: a nested node, a slice of nodes or
+// scalars, or a plain scalar via its default %v formatting.
+func dumpValue(b *strings.Builder, fv reflect.Value, depth int) {
+	if fv.Type().Implements(nodeType) {
+		if fv.IsNil() {
+			b.WriteString("nil")
+			return
+		}
+		dumpNode(b, fv, depth)
+		return
+	}
+	if fv.Kind() == reflect.Slice {
+		if fv.Len() == 0 {
+			b.WriteString("[]")
+			return
+		}
+		b.WriteString("[\n")
+		for i := 0; i < fv.Len(); i++ {
+			dumpIndent(b, depth+1)
+			dumpValue(b, fv.Index(i), depth+1)
+			b.WriteString(",\n")
+		}
+		dumpIndent(b, depth)
+		b.WriteByte(']')
+		return
+	}
+	fmt.Fprintf(b, "%v", fv.Interface())
+}
@@ -0,0 +1,179 @@
+package ast
+
+import (
+	"testing"
+
+	"github.com/dexon-foundation/decimal"
+)
+
+func intLit(v int64) *IntegerValueNode {
+	return &IntegerValueNode{V: decimal.New(v, 0)}
+}
+
+// TestFormatRoundingSensitiveParens guards against reassociating a Div/Mod
+// child into a Mul parent: "3 * (1 / 3)" and "(3 * 1) / 3" compute different
+// decimal results once division's rounding is applied, so Format must keep
+// the parentheses around the division even though Mul and Div share
+// precedence and are otherwise free to reassociate.
+func TestFormatRoundingSensitiveParens(t *testing.T) {
+	mul := &MulOperatorNode{BinaryOperatorNode: BinaryOperatorNode{
+		Object:  intLit(3),
+		Subject: &DivOperatorNode{BinaryOperatorNode: BinaryOperatorNode{Object: intLit(1), Subject: intLit(3)}},
+	}}
+
+	out, err := Format(mul)
+	if err != nil {
+		t.Fatalf("Format: %v", err)
+	}
+	const want = "3 * (1 / 3)"
+	if string(out) != want {
+		t.Errorf("Format(3 * (1/3)) = %q, want %q", out, want)
+	}
+}
+
+// TestFormatMulAsDivChildNoExtraParens checks the converse: a Mul operand
+// under a Div parent is exact (multiplication never rounds), so Format
+// should not force parentheses there beyond the one pair
+// opRightAssocNeedsParens already adds for a same-precedence right operand.
+func TestFormatMulAsDivChildNoExtraParens(t *testing.T) {
+	div := &DivOperatorNode{BinaryOperatorNode: BinaryOperatorNode{
+		Object:  intLit(6),
+		Subject: &MulOperatorNode{BinaryOperatorNode: BinaryOperatorNode{Object: intLit(2), Subject: intLit(3)}},
+	}}
+
+	out, err := Format(div)
+	if err != nil {
+		t.Fatalf("Format: %v", err)
+	}
+	const want = "6 / (2 * 3)"
+	if string(out) != want {
+		t.Errorf("Format(6 / (2*3)) = %q, want %q", out, want)
+	}
+}
+
+func tableName(name string) *TableNameNode {
+	return &TableNameNode{Table: &IdentifierNode{Name: []byte(name)}}
+}
+
+// TestFormatJoin checks that a JOIN's left/right table refs and ON
+// condition round-trip through Format in source order, using a qualified
+// identifier on each side of the ON condition -- "a.id = b.id" -- to
+// disambiguate the two joined tables' same-named columns.
+func TestFormatJoin(t *testing.T) {
+	join := &JoinNode{
+		Type:  JoinTypeLeft,
+		Left:  tableName("a"),
+		Right: tableName("b"),
+		On: &EqualOperatorNode{BinaryOperatorNode: BinaryOperatorNode{
+			Object:  &IdentifierNode{Qualifier: &IdentifierNode{Name: []byte("a")}, Name: []byte("id")},
+			Subject: &IdentifierNode{Qualifier: &IdentifierNode{Name: []byte("b")}, Name: []byte("id")},
+		}},
+	}
+
+	out, err := Format(join)
+	if err != nil {
+		t.Fatalf("Format: %v", err)
+	}
+	const want = `"a" LEFT JOIN "b" ON "a"."id" = "b"."id"`
+	if string(out) != want {
+		t.Errorf("Format(join) = %q, want %q", out, want)
+	}
+}
+
+// TestFormatSetOp checks that a UNION ALL combining two SELECTs round-trips
+// with the set operator keyword between its two statements.
+func TestFormatSetOp(t *testing.T) {
+	selectStar := func(name string) *SelectStmtNode {
+		return &SelectStmtNode{Column: []ExprNode{&AnyValueNode{}}, Table: tableName(name)}
+	}
+	union := &SetOpStmtNode{Type: SetOpUnion, All: true, Left: selectStar("a"), Right: selectStar("b")}
+
+	out, err := Format(union)
+	if err != nil {
+		t.Fatalf("Format: %v", err)
+	}
+	const want = `SELECT * FROM "a" UNION ALL SELECT * FROM "b"`
+	if string(out) != want {
+		t.Errorf("Format(union) = %q, want %q", out, want)
+	}
+}
+
+// TestFormatWindowFunctionOver checks that a window function's OVER clause,
+// including PARTITION BY, ORDER BY, and a ROWS BETWEEN frame, round-trips.
+func TestFormatWindowFunctionOver(t *testing.T) {
+	fn := &FunctionOperatorNode{
+		Name: &IdentifierNode{Name: []byte("sum")},
+		Args: []ExprNode{&IdentifierNode{Name: []byte("x")}},
+		Over: &WindowOptionNode{
+			PartitionBy: []ExprNode{&IdentifierNode{Name: []byte("g")}},
+			OrderBy: []*OrderOptionNode{
+				{Expr: &IdentifierNode{Name: []byte("x")}, Desc: false, NullsFirst: false},
+			},
+			Frame: &FrameNode{
+				Unit:  FrameUnitRows,
+				Start: &FrameBoundNode{Kind: FrameBoundUnboundedPreceding},
+				End:   &FrameBoundNode{Kind: FrameBoundCurrentRow},
+			},
+		},
+	}
+
+	out, err := Format(fn)
+	if err != nil {
+		t.Fatalf("Format: %v", err)
+	}
+	const want = `"sum"("x") OVER (PARTITION BY "g" ORDER BY "x" ASC NULLS LAST ROWS BETWEEN UNBOUNDED PRECEDING AND CURRENT ROW)`
+	if string(out) != want {
+		t.Errorf("Format(window fn) = %q, want %q", out, want)
+	}
+}
+
+// TestFormatAlterTableMultipleActions checks that an ALTER TABLE statement
+// with several actions joins them with ", " in order.
+func TestFormatAlterTableMultipleActions(t *testing.T) {
+	stmt := &AlterTableStmtNode{
+		Table: &IdentifierNode{Name: []byte("t")},
+		Actions: []AlterTableActionNode{
+			&AddColumnActionNode{Column: &ColumnSchemaNode{
+				Column:   &IdentifierNode{Name: []byte("c")},
+				DataType: &IntTypeNode{Size: 64},
+			}},
+			&DropColumnActionNode{Column: &IdentifierNode{Name: []byte("d")}},
+			&RenameTableActionNode{To: &IdentifierNode{Name: []byte("t2")}},
+		},
+	}
+
+	out, err := Format(stmt)
+	if err != nil {
+		t.Fatalf("Format: %v", err)
+	}
+	const want = `ALTER TABLE "t" ADD COLUMN "c" int64, DROP COLUMN "d", RENAME TO "t2"`
+	if string(out) != want {
+		t.Errorf("Format(alter table) = %q, want %q", out, want)
+	}
+}
+
+// TestFormatSubqueryAndExists checks that a subquery operand and an EXISTS
+// predicate each wrap their inner SELECT in parentheses.
+func TestFormatSubqueryAndExists(t *testing.T) {
+	inner := &SelectStmtNode{Column: []ExprNode{&AnyValueNode{}}, Table: tableName("t")}
+
+	sub := &SubqueryOperatorNode{Query: inner}
+	out, err := Format(sub)
+	if err != nil {
+		t.Fatalf("Format: %v", err)
+	}
+	const wantSub = `(SELECT * FROM "t")`
+	if string(out) != wantSub {
+		t.Errorf("Format(subquery) = %q, want %q", out, wantSub)
+	}
+
+	exists := &ExistsOperatorNode{Query: inner}
+	out, err = Format(exists)
+	if err != nil {
+		t.Fatalf("Format: %v", err)
+	}
+	const wantExists = `EXISTS (SELECT * FROM "t")`
+	if string(out) != wantExists {
+		t.Errorf("Format(exists) = %q, want %q", out, wantExists)
+	}
+}
@@ -0,0 +1,220 @@
+package ast
+
+import (
+	"bytes"
+
+	"github.com/dexon-foundation/decimal"
+)
+
+// Value is a NULL-aware runtime value exchanged between the executor,
+// aggregate functions, and expression evaluation. It unifies BoolValue's
+// three-valued logic with the numeric, address, and bytes representations
+// used elsewhere in this package, so callers no longer need to track
+// validity out-of-band across a scatter of decimal.Decimal/[]byte/BoolValue
+// unions.
+type Value struct {
+	DataType DataType
+	Null     bool
+	Decimal  decimal.Decimal
+	Bytes    []byte
+	Bool     BoolValue
+}
+
+// NewNullValue constructs a NULL value of the given type.
+func NewNullValue(dt DataType) Value {
+	return Value{DataType: dt, Null: true}
+}
+
+// NewBoolValueFrom constructs a Value from a BoolValue, collapsing
+// BoolValueUnknown to a NULL bool Value so the two representations of
+// "no definite answer" stay equivalent.
+func NewBoolValueFrom(v BoolValue) Value {
+	dt := ComposeDataType(DataTypeMajorBool, DataTypeMinorDontCare)
+	if v == BoolValueUnknown {
+		return NewNullValue(dt)
+	}
+	return Value{DataType: dt, Bool: v}
+}
+
+// NewDecimalValue constructs a non-NULL Value for any decimal-backed type:
+// address, int, uint, fixed, ufixed, date, time, timestamp, or interval.
+func NewDecimalValue(dt DataType, d decimal.Decimal) Value {
+	return Value{DataType: dt, Decimal: d}
+}
+
+// NewBytesValue constructs a non-NULL Value for a fixed or dynamic bytes
+// type.
+func NewBytesValue(dt DataType, b []byte) Value {
+	return Value{DataType: dt, Bytes: b}
+}
+
+// IsNull returns whether v is NULL.
+func (v Value) IsNull() bool {
+	return v.Null
+}
+
+// AsBoolValue returns v's three-valued boolean representation. It panics if
+// v is not of a bool type.
+func (v Value) AsBoolValue() BoolValue {
+	if major, _ := DecomposeDataType(v.DataType); major != DataTypeMajorBool {
+		panic("AsBoolValue called on a non-bool Value")
+	}
+	if v.Null {
+		return BoolValueUnknown
+	}
+	return v.Bool
+}
+
+// IsNullValue implements 'IS NULL'. Unlike other operators it never itself
+// produces NULL.
+func (v Value) IsNullValue() Value {
+	return NewBoolValueFrom(boolValueFromBool(v.Null))
+}
+
+// IsNotNullValue implements 'IS NOT NULL'. Unlike other operators it never
+// itself produces NULL.
+func (v Value) IsNotNullValue() Value {
+	return NewBoolValueFrom(boolValueFromBool(!v.Null))
+}
+
+func boolValueFromBool(b bool) BoolValue {
+	if b {
+		return BoolValueTrue
+	}
+	return BoolValueFalse
+}
+
+// Equal returns v = v2. NULL propagates: NULL = anything is NULL.
+func (v Value) Equal(v2 Value) Value {
+	if v.Null || v2.Null {
+		return NewNullValue(ComposeDataType(DataTypeMajorBool, DataTypeMinorDontCare))
+	}
+	major, _ := DecomposeDataType(v.DataType)
+	switch major {
+	case DataTypeMajorBool:
+		return NewBoolValueFrom(boolValueFromBool(v.Bool == v2.Bool))
+	case DataTypeMajorFixedBytes, DataTypeMajorDynamicBytes:
+		return NewBoolValueFrom(boolValueFromBool(bytes.Equal(v.Bytes, v2.Bytes)))
+	default:
+		return NewBoolValueFrom(boolValueFromBool(v.Decimal.Equal(v2.Decimal)))
+	}
+}
+
+// NotEqual returns v <> v2. NULL propagates: NULL <> anything is NULL.
+func (v Value) NotEqual(v2 Value) Value {
+	eq := v.Equal(v2)
+	if eq.Null {
+		return eq
+	}
+	return NewBoolValueFrom(eq.Bool.Not())
+}
+
+// compareDecimal orders v against v2 for the ordering operators, which are
+// only defined on decimal-backed types. NULL propagates: NULL compared to
+// anything is NULL.
+func (v Value) compareDecimal(v2 Value, pred func(int) bool) Value {
+	dtBool := ComposeDataType(DataTypeMajorBool, DataTypeMinorDontCare)
+	if v.Null || v2.Null {
+		return NewNullValue(dtBool)
+	}
+	return NewBoolValueFrom(boolValueFromBool(pred(v.Decimal.Cmp(v2.Decimal))))
+}
+
+// Greater returns v > v2.
+func (v Value) Greater(v2 Value) Value {
+	return v.compareDecimal(v2, func(c int) bool { return c > 0 })
+}
+
+// GreaterOrEqual returns v >= v2.
+func (v Value) GreaterOrEqual(v2 Value) Value {
+	return v.compareDecimal(v2, func(c int) bool { return c >= 0 })
+}
+
+// Less returns v < v2.
+func (v Value) Less(v2 Value) Value {
+	return v.compareDecimal(v2, func(c int) bool { return c < 0 })
+}
+
+// LessOrEqual returns v <= v2.
+func (v Value) LessOrEqual(v2 Value) Value {
+	return v.compareDecimal(v2, func(c int) bool { return c <= 0 })
+}
+
+// arithDecimal applies a decimal operator to v and v2, propagating NULL:
+// NULL op anything is NULL. The result is typed dt.
+func (v Value) arithDecimal(v2 Value, dt DataType, op func(a, b decimal.Decimal) decimal.Decimal) Value {
+	if v.Null || v2.Null {
+		return NewNullValue(dt)
+	}
+	return NewDecimalValue(dt, op(v.Decimal, v2.Decimal))
+}
+
+// Add returns v + v2.
+func (v Value) Add(v2 Value) Value {
+	return v.arithDecimal(v2, v.DataType, decimal.Decimal.Add)
+}
+
+// Sub returns v - v2, typed INTERVAL rather than v.DataType when both
+// operands are TIMESTAMP: SQL defines TIMESTAMP - TIMESTAMP as the signed
+// duration between the two instants, not another TIMESTAMP. Every other
+// combination (e.g. TIMESTAMP - INTERVAL) keeps v.DataType, unchanged.
+func (v Value) Sub(v2 Value) Value {
+	dt := v.DataType
+	major, minor := DecomposeDataType(v.DataType)
+	major2, _ := DecomposeDataType(v2.DataType)
+	if major == DataTypeMajorTimestamp && major2 == DataTypeMajorTimestamp {
+		_, precision := decomposeTemporalMinor(minor)
+		dt = ComposeDataType(DataTypeMajorInterval, composeTemporalMinor(false, precision))
+	}
+	return v.arithDecimal(v2, dt, decimal.Decimal.Sub)
+}
+
+// Mul returns v * v2.
+func (v Value) Mul(v2 Value) Value {
+	return v.arithDecimal(v2, v.DataType, decimal.Decimal.Mul)
+}
+
+// Encode serializes a non-NULL Value's payload using the same on-disk
+// encoding as DecimalEncode for fixed-width columns. NULL-ness is tracked by
+// a separate per-row null bitmap kept by the caller, so no bytes are spent
+// here encoding it, keeping on-chain storage compact.
+func (v Value) Encode() ([]byte, error) {
+	if v.Null {
+		panic("Encode called on a NULL Value")
+	}
+	major, _ := DecomposeDataType(v.DataType)
+	switch major {
+	case DataTypeMajorBool:
+		b := byte(0)
+		if v.Bool == BoolValueTrue {
+			b = 1
+		}
+		return []byte{b}, nil
+	case DataTypeMajorFixedBytes, DataTypeMajorDynamicBytes:
+		return v.Bytes, nil
+	default:
+		return DecimalEncode(v.DataType, v.Decimal)
+	}
+}
+
+// DecodeValue deserializes a Value's payload for the given type. NULL-ness
+// must be supplied by the caller via a separate per-row null bitmap, as
+// DecodeValue has no sentinel encoding of its own to detect it from b.
+func DecodeValue(dt DataType, null bool, b []byte) (Value, error) {
+	if null {
+		return NewNullValue(dt), nil
+	}
+	major, _ := DecomposeDataType(dt)
+	switch major {
+	case DataTypeMajorBool:
+		return Value{DataType: dt, Bool: boolValueFromBool(len(b) > 0 && b[0] != 0)}, nil
+	case DataTypeMajorFixedBytes, DataTypeMajorDynamicBytes:
+		return Value{DataType: dt, Bytes: b}, nil
+	default:
+		d, err := DecimalDecode(dt, b)
+		if err != nil {
+			return Value{}, err
+		}
+		return Value{DataType: dt, Decimal: d}, nil
+	}
+}
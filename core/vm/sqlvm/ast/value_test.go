@@ -0,0 +1,87 @@
+package ast
+
+import (
+	"testing"
+
+	"github.com/dexon-foundation/decimal"
+)
+
+func timestampValue(sec int64) Value {
+	dt := ComposeDataType(DataTypeMajorTimestamp, composeTemporalMinor(false, TimePrecisionSecond))
+	return NewDecimalValue(dt, decimal.New(sec, 0))
+}
+
+// TestSubTimestampMinusTimestampIsInterval checks that TIMESTAMP - TIMESTAMP
+// is typed INTERVAL rather than mistyped as TIMESTAMP, the type an
+// unqualified v.DataType result would otherwise carry forward.
+func TestSubTimestampMinusTimestampIsInterval(t *testing.T) {
+	a := timestampValue(100)
+	b := timestampValue(40)
+
+	got := a.Sub(b)
+
+	major, _ := DecomposeDataType(got.DataType)
+	if major != DataTypeMajorInterval {
+		t.Fatalf("Sub(TIMESTAMP, TIMESTAMP).DataType major = %v, want DataTypeMajorInterval", major)
+	}
+	if !got.Decimal.Equal(decimal.New(60, 0)) {
+		t.Errorf("Sub(TIMESTAMP, TIMESTAMP).Decimal = %s, want 60", got.Decimal)
+	}
+}
+
+// TestSubTimestampMinusIntervalStaysTimestamp checks the Sub case the fix
+// must not disturb: TIMESTAMP - INTERVAL is still a TIMESTAMP.
+func TestSubTimestampMinusIntervalStaysTimestamp(t *testing.T) {
+	ts := timestampValue(100)
+	iv := NewDecimalValue(
+		ComposeDataType(DataTypeMajorInterval, composeTemporalMinor(false, TimePrecisionSecond)),
+		decimal.New(30, 0),
+	)
+
+	got := ts.Sub(iv)
+
+	major, _ := DecomposeDataType(got.DataType)
+	if major != DataTypeMajorTimestamp {
+		t.Errorf("Sub(TIMESTAMP, INTERVAL).DataType major = %v, want DataTypeMajorTimestamp", major)
+	}
+}
+
+// TestSubNullPropagates checks that a NULL operand produces a NULL result
+// typed the same way a non-NULL Sub would be.
+func TestSubNullPropagates(t *testing.T) {
+	a := timestampValue(100)
+	null := NewNullValue(a.DataType)
+
+	got := a.Sub(null)
+
+	if !got.IsNull() {
+		t.Errorf("Sub(TIMESTAMP, NULL).IsNull() = false, want true")
+	}
+	major, _ := DecomposeDataType(got.DataType)
+	if major != DataTypeMajorInterval {
+		t.Errorf("Sub(TIMESTAMP, NULL).DataType major = %v, want DataTypeMajorInterval", major)
+	}
+}
+
+// TestEqualDispatchesOnMajor checks Equal's three branches: bool compares
+// Bool, bytes compares Bytes, and everything else compares Decimal.
+func TestEqualDispatchesOnMajor(t *testing.T) {
+	boolDt := ComposeDataType(DataTypeMajorBool, DataTypeMinorDontCare)
+	if v := (Value{DataType: boolDt, Bool: BoolValueTrue}).Equal(Value{DataType: boolDt, Bool: BoolValueTrue}); v.AsBoolValue() != BoolValueTrue {
+		t.Errorf("true.Equal(true) = %v, want true", v.AsBoolValue())
+	}
+
+	bytesDt := ComposeDataType(DataTypeMajorFixedBytes, DataTypeMinor(3))
+	a := NewBytesValue(bytesDt, []byte{1, 2, 3, 4})
+	b := NewBytesValue(bytesDt, []byte{1, 2, 3, 5})
+	if v := a.Equal(b); v.AsBoolValue() != BoolValueFalse {
+		t.Errorf("differing bytes.Equal = %v, want false", v.AsBoolValue())
+	}
+
+	intDt := ComposeDataType(DataTypeMajorInt, DataTypeMinor(7))
+	x := NewDecimalValue(intDt, decimal.New(1, 0))
+	y := NewDecimalValue(intDt, decimal.New(1, 0))
+	if v := x.Equal(y); v.AsBoolValue() != BoolValueTrue {
+		t.Errorf("1.Equal(1) = %v, want true", v.AsBoolValue())
+	}
+}
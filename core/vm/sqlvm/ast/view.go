@@ -0,0 +1,166 @@
+package ast
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/dexon-foundation/dexon/core/vm/sqlvm/errors"
+)
+
+// ViewError records a diagnostic produced while expanding a view reference.
+type ViewError struct {
+	Node    Node
+	Code    errors.ErrorCode
+	Message string
+}
+
+// ExpandViews walks n and replaces every TableNameNode whose Table
+// identifier names a key in views with a DerivedTableNode wrapping a fresh
+// clone of that view's defining query, aliased to the name (or explicit
+// alias) the statement used to refer to it. Expansion recurses into the
+// substituted query so views built on top of other views are fully
+// expanded; a view that references itself, directly or through another
+// view, is reported as a ViewError and left unexpanded rather than
+// recursing forever.
+//
+// Every expansion site gets its own clone of view.Query (see cloneTree), so
+// later in-place rewrites -- type resolution, constant folding -- on one
+// expansion never reach another. Each clone also has its table aliases
+// alpha-renamed (see alphaRenameTableAliases) to a name unique to that
+// expansion site, so two expansions of the same view nested in the same
+// statement can never introduce identical aliases.
+func ExpandViews(n Node, views map[string]*CreateViewStmtNode) (Node, []ViewError) {
+	var errs []ViewError
+	seq := 0
+	result := expandViews(n, views, map[string]bool{}, &errs, &seq)
+	return result, errs
+}
+
+func expandViews(n Node, views map[string]*CreateViewStmtNode, active map[string]bool, errs *[]ViewError, seq *int) Node {
+	return Rewrite(n, func(node Node) Node {
+		ref, ok := node.(*TableNameNode)
+		if !ok {
+			return node
+		}
+		name := string(ref.Table.Name)
+		view, ok := views[name]
+		if !ok {
+			return node
+		}
+		if active[name] {
+			*errs = append(*errs, ViewError{
+				Node:    node,
+				Code:    errors.ErrorCodeRecursiveView,
+				Message: "view \"" + name + "\" references itself",
+			})
+			return node
+		}
+
+		cloned := cloneTree(view.Query).(StmtNode)
+		alphaRenameTableAliases(cloned, seq)
+
+		active[name] = true
+		expanded := expandViews(cloned, views, active, errs, seq)
+		active[name] = false
+
+		query, ok := expanded.(StmtNode)
+		if !ok {
+			return node
+		}
+
+		alias := ref.Alias
+		if alias == nil {
+			alias = ref.Table
+		}
+		derived := &DerivedTableNode{Query: query, Alias: alias}
+		UpdatePosition(derived, node, node)
+		return derived
+	})
+}
+
+// cloneTree returns a deep copy of n: every Node-typed field, and every
+// element of a []T slice where T implements Node, is itself recursively
+// cloned into a freshly allocated slice, so the returned tree shares no
+// node with n. Two exceptions: a [][]T field (InsertWithColumnOptionNode.Value)
+// is not descended into, so its nodes stay shared with n; and scalar fields
+// (decimal.Decimal, []byte, NodeBase's position/length, ...) are copied by
+// the initial struct assignment, so a []byte field still shares its backing
+// array with n.
+func cloneTree(n Node) Node {
+	if n == nil {
+		return nil
+	}
+	v := reflect.ValueOf(n)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return n
+	}
+	clone := reflect.New(v.Elem().Type())
+	clone.Elem().Set(v.Elem())
+	cloneFieldsInPlace(clone.Elem())
+	return clone.Interface().(Node)
+}
+
+// cloneFieldsInPlace replaces every child-node-holding field of v (already
+// a shallow copy of its source) with a deep clone of that child, following
+// the same "implements Node, or is a slice of such" field recognition
+// rewriteReflectStruct uses to auto-traverse node types with no hand-written
+// case.
+func cloneFieldsInPlace(v reflect.Value) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue // unexported
+		}
+		fv := v.Field(i)
+		switch {
+		case field.Anonymous && fv.Kind() == reflect.Struct:
+			cloneFieldsInPlace(fv)
+		case fv.Type().Implements(nodeType):
+			if !fv.IsNil() {
+				fv.Set(reflect.ValueOf(cloneTree(fv.Interface().(Node))))
+			}
+		case fv.Kind() == reflect.Slice && fv.Type().Elem().Implements(nodeType):
+			if fv.IsNil() {
+				continue
+			}
+			// Detach from the source's backing array before writing clones.
+			cp := reflect.MakeSlice(fv.Type(), fv.Len(), fv.Len())
+			reflect.Copy(cp, fv)
+			fv.Set(cp)
+			for j := 0; j < fv.Len(); j++ {
+				ev := fv.Index(j)
+				if ev.IsNil() {
+					continue
+				}
+				ev.Set(reflect.ValueOf(cloneTree(ev.Interface().(Node))))
+			}
+		}
+	}
+}
+
+// alphaRenameTableAliases rewrites every table reference's alias within
+// tree to a name unique to this expansion site (Table$N, Alias$N for the
+// Nth table reference touched across the whole ExpandViews call). Since
+// cloneTree already gives each expansion site its own independent copy of
+// the view's table references, this only needs to rule out two expansions
+// of the same or different views minting the identical alias when nested
+// in the same statement -- not to detect an actual clash, which a
+// monotonic counter already guarantees can't happen.
+func alphaRenameTableAliases(tree Node, seq *int) {
+	Inspect(tree, func(n Node) bool {
+		ref, ok := n.(*TableNameNode)
+		if !ok {
+			return true
+		}
+		base := ref.Alias
+		if base == nil {
+			base = ref.Table
+		}
+		*seq++
+		alias := &IdentifierNode{Name: []byte(fmt.Sprintf("%s$%d", base.Name, *seq))}
+		UpdatePosition(alias, base, base)
+		ref.Alias = alias
+		return true
+	})
+}
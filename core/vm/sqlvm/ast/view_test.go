@@ -0,0 +1,67 @@
+package ast
+
+import (
+	"testing"
+
+	"github.com/dexon-foundation/dexon/core/vm/sqlvm/errors"
+)
+
+// TestExpandViewsReplacesTableName checks the common case: a TableNameNode
+// referencing a known view is replaced by a DerivedTableNode wrapping a
+// clone of the view's query, aliased to the name the statement used.
+func TestExpandViewsReplacesTableName(t *testing.T) {
+	view := &CreateViewStmtNode{
+		View: &IdentifierNode{Name: []byte("v")},
+		Query: &SelectStmtNode{
+			Column: []ExprNode{&AnyValueNode{}},
+			Table:  &TableNameNode{Table: &IdentifierNode{Name: []byte("t")}},
+		},
+	}
+	views := map[string]*CreateViewStmtNode{"v": view}
+
+	stmt := &SelectStmtNode{
+		Column: []ExprNode{&AnyValueNode{}},
+		Table:  &TableNameNode{Table: &IdentifierNode{Name: []byte("v")}},
+	}
+
+	out, errs := ExpandViews(stmt, views)
+	if len(errs) != 0 {
+		t.Fatalf("ExpandViews: unexpected errors %v", errs)
+	}
+	got := out.(*SelectStmtNode)
+	derived, ok := got.Table.(*DerivedTableNode)
+	if !ok {
+		t.Fatalf("Table = %#v, want *DerivedTableNode", got.Table)
+	}
+	if string(derived.Alias.Name) != "v" {
+		t.Errorf("Alias = %q, want %q", derived.Alias.Name, "v")
+	}
+	if derived.Query == view.Query {
+		t.Error("expanded query shares the view's original query node, want a clone")
+	}
+}
+
+// TestExpandViewsSelfReferenceReportsError checks that a view referencing
+// itself is reported as a ViewError and left unexpanded rather than
+// recursing forever.
+func TestExpandViewsSelfReferenceReportsError(t *testing.T) {
+	view := &CreateViewStmtNode{View: &IdentifierNode{Name: []byte("v")}}
+	view.Query = &SelectStmtNode{
+		Column: []ExprNode{&AnyValueNode{}},
+		Table:  &TableNameNode{Table: &IdentifierNode{Name: []byte("v")}},
+	}
+	views := map[string]*CreateViewStmtNode{"v": view}
+
+	stmt := &SelectStmtNode{
+		Column: []ExprNode{&AnyValueNode{}},
+		Table:  &TableNameNode{Table: &IdentifierNode{Name: []byte("v")}},
+	}
+
+	_, errs := ExpandViews(stmt, views)
+	if len(errs) != 1 {
+		t.Fatalf("ExpandViews: got %d errors, want 1", len(errs))
+	}
+	if errs[0].Code != errors.ErrorCodeRecursiveView {
+		t.Errorf("ExpandViews error code = %v, want ErrorCodeRecursiveView", errs[0].Code)
+	}
+}
@@ -0,0 +1,549 @@
+package ast
+
+import "reflect"
+
+// Visitor's Visit method is invoked for each node encountered by Walk. If
+// the result visitor w is not nil, Walk visits each of the children of node
+// with the visitor w, followed by a call of w.Visit(nil).
+type Visitor interface {
+	Visit(node Node) (w Visitor)
+}
+
+// Walk traverses an AST in depth-first order: it starts by calling
+// v.Visit(node); node must not be nil. If the visitor w returned by
+// v.Visit(node) is not nil, Walk is invoked recursively with visitor w for
+// each of the non-nil children of node, followed by a call of w.Visit(nil).
+func Walk(v Visitor, node Node) {
+	if node == nil {
+		return
+	}
+	v = v.Visit(node)
+	if v == nil {
+		return
+	}
+	for _, child := range node.GetChildren() {
+		Walk(v, child)
+	}
+	v.Visit(nil)
+}
+
+type inspector func(Node) bool
+
+func (f inspector) Visit(node Node) Visitor {
+	if f(node) {
+		return f
+	}
+	return nil
+}
+
+// Inspect traverses an AST in depth-first order: it starts by calling
+// f(node); node must not be nil. If f returns true, Inspect invokes f
+// recursively for each of the non-nil children of node, followed by a call
+// of f(nil).
+func Inspect(node Node, f func(Node) bool) {
+	Walk(inspector(f), node)
+}
+
+// Edit traverses n in depth-first order, calling pre on a node before
+// descending into its children and post after its children have themselves
+// been fully edited. Either may return a replacement node (including nil to
+// delete it, where the parent's field allows a nil); either may be nil to
+// skip that edge. Edit is Rewrite generalized to give a caller a hook on the
+// way down as well as the way up -- a pre hook that wants to stop a subtree
+// from being descended into at all (to leave a quoted/unexpanded region
+// alone, say) can simply not recurse by returning a leaf replacement, since
+// whatever pre returns is what gets passed to rewriteChildren.
+//
+// This is named Edit rather than Walk to avoid colliding with the
+// Visitor-based Walk above -- Go does not allow two package-level Walks
+// with different signatures, and that Walk is the established read-only
+// traversal entry point.
+func Edit(n Node, pre, post func(Node) Node) Node {
+	if n == nil {
+		return nil
+	}
+	if pre != nil {
+		n = pre(n)
+		if n == nil {
+			return nil
+		}
+	}
+	rewriteChildren(n, func(child Node) Node {
+		return Edit(child, pre, post)
+	})
+	if post != nil {
+		return post(n)
+	}
+	return n
+}
+
+// Rewrite traverses n in post-order, first rewriting each of n's children in
+// place and then passing n itself through f, returning f's result. f may
+// return a different concrete node to replace the subtree rooted at n
+// (including nil to delete it, where the parent's field allows a nil).
+// Rewrite(n, f) is Edit(n, nil, f).
+//
+// GetChildren only exposes a read-only snapshot of a node's children, so
+// there is no generic way to write a replacement back into its parent.
+// rewriteChildren below is the setter table: one case per concrete node
+// type that owns children, reconstructing its fields after recursing into
+// them. Callers should not need to type-switch on every node themselves;
+// this is the one place that does.
+func Rewrite(n Node, f func(Node) Node) Node {
+	return Edit(n, nil, f)
+}
+
+// EditChildren replaces each of n's children, recursively, with the result
+// of running them through f via Edit, and returns n itself untouched --
+// unlike Rewrite/Edit, f never runs on n, only on its descendants. It is the
+// setter table (rewriteChildren) exposed as a public, reusable primitive
+// instead of a private implementation detail of Rewrite, for callers that
+// want to edit a subtree's contents without also deciding what happens to
+// its root.
+//
+// Like Edit, and unlike GetChildren/Dump, EditChildren does not treat a
+// `print:"-"` tag as a reason to skip a field: that tag only marks a field
+// hidden from dumps, not exempt from rewriting. A field tagged `print:"-"`
+// that holds a Node is still visited.
+func EditChildren(n Node, f func(Node) Node) Node {
+	if n == nil {
+		return nil
+	}
+	rewriteChildren(n, func(child Node) Node {
+		return Edit(child, nil, f)
+	})
+	return n
+}
+
+func rewriteExpr(n ExprNode, recurse func(Node) Node) ExprNode {
+	if n == nil {
+		return nil
+	}
+	r := recurse(n)
+	if r == nil {
+		return nil
+	}
+	return r.(ExprNode)
+}
+
+func rewriteType(n TypeNode, recurse func(Node) Node) TypeNode {
+	if n == nil {
+		return nil
+	}
+	r := recurse(n)
+	if r == nil {
+		return nil
+	}
+	return r.(TypeNode)
+}
+
+func rewriteIdentifier(n *IdentifierNode, recurse func(Node) Node) *IdentifierNode {
+	if n == nil {
+		return nil
+	}
+	r := recurse(n)
+	if r == nil {
+		return nil
+	}
+	return r.(*IdentifierNode)
+}
+
+func rewriteTableRef(n TableRefNode, recurse func(Node) Node) TableRefNode {
+	if n == nil {
+		return nil
+	}
+	r := recurse(n)
+	if r == nil {
+		return nil
+	}
+	return r.(TableRefNode)
+}
+
+func rewriteStmt(n StmtNode, recurse func(Node) Node) StmtNode {
+	if n == nil {
+		return nil
+	}
+	r := recurse(n)
+	if r == nil {
+		return nil
+	}
+	return r.(StmtNode)
+}
+
+func rewriteWhere(n *WhereOptionNode, recurse func(Node) Node) *WhereOptionNode {
+	if n == nil {
+		return nil
+	}
+	r := recurse(n)
+	if r == nil {
+		return nil
+	}
+	return r.(*WhereOptionNode)
+}
+
+func rewriteGroup(n *GroupOptionNode, recurse func(Node) Node) *GroupOptionNode {
+	if n == nil {
+		return nil
+	}
+	r := recurse(n)
+	if r == nil {
+		return nil
+	}
+	return r.(*GroupOptionNode)
+}
+
+func rewriteOrder(n *OrderOptionNode, recurse func(Node) Node) *OrderOptionNode {
+	if n == nil {
+		return nil
+	}
+	r := recurse(n)
+	if r == nil {
+		return nil
+	}
+	return r.(*OrderOptionNode)
+}
+
+func rewriteWindow(n *WindowOptionNode, recurse func(Node) Node) *WindowOptionNode {
+	if n == nil {
+		return nil
+	}
+	r := recurse(n)
+	if r == nil {
+		return nil
+	}
+	return r.(*WindowOptionNode)
+}
+
+func rewriteFrame(n *FrameNode, recurse func(Node) Node) *FrameNode {
+	if n == nil {
+		return nil
+	}
+	r := recurse(n)
+	if r == nil {
+		return nil
+	}
+	return r.(*FrameNode)
+}
+
+func rewriteFrameBound(n *FrameBoundNode, recurse func(Node) Node) *FrameBoundNode {
+	if n == nil {
+		return nil
+	}
+	r := recurse(n)
+	if r == nil {
+		return nil
+	}
+	return r.(*FrameBoundNode)
+}
+
+func rewriteLimit(n *LimitOptionNode, recurse func(Node) Node) *LimitOptionNode {
+	if n == nil {
+		return nil
+	}
+	r := recurse(n)
+	if r == nil {
+		return nil
+	}
+	return r.(*LimitOptionNode)
+}
+
+func rewriteOffset(n *OffsetOptionNode, recurse func(Node) Node) *OffsetOptionNode {
+	if n == nil {
+		return nil
+	}
+	r := recurse(n)
+	if r == nil {
+		return nil
+	}
+	return r.(*OffsetOptionNode)
+}
+
+func rewriteAssign(n *AssignOperatorNode, recurse func(Node) Node) *AssignOperatorNode {
+	if n == nil {
+		return nil
+	}
+	r := recurse(n)
+	if r == nil {
+		return nil
+	}
+	return r.(*AssignOperatorNode)
+}
+
+func rewriteUnique(n *UniqueOptionNode, recurse func(Node) Node) *UniqueOptionNode {
+	if n == nil {
+		return nil
+	}
+	r := recurse(n)
+	if r == nil {
+		return nil
+	}
+	return r.(*UniqueOptionNode)
+}
+
+// rewriteChildren rewrites n's children in place, dispatching on n's
+// concrete type since the Node interface offers no generic setter. recurse
+// is called on each immediate child and already handles that child's own
+// descendants (and, for Edit, its pre/post hooks) -- cases below must call
+// recurse exactly once per child rather than recursing themselves.
+func rewriteChildren(n Node, recurse func(Node) Node) {
+	switch node := n.(type) {
+	case *LikeOperatorNode:
+		node.Object = rewriteExpr(node.Object, recurse)
+		node.Subject = rewriteExpr(node.Subject, recurse)
+		node.Escape = rewriteExpr(node.Escape, recurse)
+	case *InOperatorNode:
+		node.Left = rewriteExpr(node.Left, recurse)
+		if node.Query != nil {
+			node.Query = rewriteStmt(node.Query, recurse)
+		} else {
+			for i, r := range node.Right {
+				node.Right[i] = rewriteExpr(r, recurse)
+			}
+		}
+	case *CastOperatorNode:
+		node.SourceExpr = rewriteExpr(node.SourceExpr, recurse)
+		node.TargetType = rewriteType(node.TargetType, recurse)
+	case *AssignOperatorNode:
+		node.Column = rewriteIdentifier(node.Column, recurse)
+		node.Expr = rewriteExpr(node.Expr, recurse)
+	case UnaryOperator:
+		node.SetTarget(rewriteExpr(node.GetTarget(), recurse))
+	case BinaryOperator:
+		node.SetObject(rewriteExpr(node.GetObject(), recurse))
+		node.SetSubject(rewriteExpr(node.GetSubject(), recurse))
+	case *FunctionOperatorNode:
+		node.Name = rewriteIdentifier(node.Name, recurse)
+		for i, a := range node.Args {
+			node.Args[i] = rewriteExpr(a, recurse)
+		}
+		if node.Over != nil {
+			node.Over = rewriteWindow(node.Over, recurse)
+		}
+	case *WhereOptionNode:
+		node.Condition = rewriteExpr(node.Condition, recurse)
+	case *OrderOptionNode:
+		node.Expr = rewriteExpr(node.Expr, recurse)
+	case *GroupOptionNode:
+		node.Expr = rewriteExpr(node.Expr, recurse)
+	case *WindowOptionNode:
+		for i, p := range node.PartitionBy {
+			node.PartitionBy[i] = rewriteExpr(p, recurse)
+		}
+		for i, o := range node.OrderBy {
+			node.OrderBy[i] = rewriteOrder(o, recurse)
+		}
+		if node.Frame != nil {
+			node.Frame = rewriteFrame(node.Frame, recurse)
+		}
+	case *FrameNode:
+		node.Start = rewriteFrameBound(node.Start, recurse)
+		node.End = rewriteFrameBound(node.End, recurse)
+	case *FrameBoundNode:
+		node.Offset = rewriteExpr(node.Offset, recurse)
+	case *OffsetOptionNode:
+		if r := recurse(node.Value); r != nil {
+			node.Value = r.(*IntegerValueNode)
+		} else {
+			node.Value = nil
+		}
+	case *LimitOptionNode:
+		if r := recurse(node.Value); r != nil {
+			node.Value = r.(*IntegerValueNode)
+		} else {
+			node.Value = nil
+		}
+	case *InsertWithColumnOptionNode:
+		for i, c := range node.Column {
+			node.Column[i] = rewriteIdentifier(c, recurse)
+		}
+		for i, row := range node.Value {
+			for j, v := range row {
+				node.Value[i][j] = rewriteExpr(v, recurse)
+			}
+		}
+	case *DefaultOptionNode:
+		node.Value = rewriteExpr(node.Value, recurse)
+	case *ForeignOptionNode:
+		node.Table = rewriteIdentifier(node.Table, recurse)
+		node.Column = rewriteIdentifier(node.Column, recurse)
+	case *TableNameNode:
+		node.Table = rewriteIdentifier(node.Table, recurse)
+		node.Alias = rewriteIdentifier(node.Alias, recurse)
+	case *JoinNode:
+		node.Left = rewriteTableRef(node.Left, recurse)
+		node.Right = rewriteTableRef(node.Right, recurse)
+		node.On = rewriteExpr(node.On, recurse)
+	case *DerivedTableNode:
+		node.Query = rewriteStmt(node.Query, recurse)
+		node.Alias = rewriteIdentifier(node.Alias, recurse)
+	case *SelectStmtNode:
+		for i, c := range node.Column {
+			node.Column[i] = rewriteExpr(c, recurse)
+		}
+		node.Table = rewriteTableRef(node.Table, recurse)
+		if node.Where != nil {
+			node.Where = rewriteWhere(node.Where, recurse)
+		}
+		for i, g := range node.Group {
+			node.Group[i] = rewriteGroup(g, recurse)
+		}
+		for i, o := range node.Order {
+			node.Order[i] = rewriteOrder(o, recurse)
+		}
+		if node.Limit != nil {
+			node.Limit = rewriteLimit(node.Limit, recurse)
+		}
+		if node.Offset != nil {
+			node.Offset = rewriteOffset(node.Offset, recurse)
+		}
+	case *SetOpStmtNode:
+		node.Left = rewriteStmt(node.Left, recurse)
+		node.Right = rewriteStmt(node.Right, recurse)
+	case *UpdateStmtNode:
+		node.Table = rewriteTableRef(node.Table, recurse)
+		for i, a := range node.Assignment {
+			node.Assignment[i] = rewriteAssign(a, recurse)
+		}
+		if node.Where != nil {
+			node.Where = rewriteWhere(node.Where, recurse)
+		}
+	case *DeleteStmtNode:
+		node.Table = rewriteTableRef(node.Table, recurse)
+		if node.Where != nil {
+			node.Where = rewriteWhere(node.Where, recurse)
+		}
+	case *InsertStmtNode:
+		node.Table = rewriteIdentifier(node.Table, recurse)
+		if node.Insert != nil {
+			node.Insert = recurse(node.Insert)
+		}
+	case *CreateTableStmtNode:
+		node.Table = rewriteIdentifier(node.Table, recurse)
+		for i, c := range node.Column {
+			if r := recurse(c); r != nil {
+				node.Column[i] = r.(*ColumnSchemaNode)
+			}
+		}
+	case *ColumnSchemaNode:
+		node.Column = rewriteIdentifier(node.Column, recurse)
+		node.DataType = rewriteType(node.DataType, recurse)
+		for i, c := range node.Constraint {
+			node.Constraint[i] = recurse(c)
+		}
+	case *CreateIndexStmtNode:
+		node.Index = rewriteIdentifier(node.Index, recurse)
+		node.Table = rewriteIdentifier(node.Table, recurse)
+		for i, c := range node.Column {
+			node.Column[i] = rewriteIdentifier(c, recurse)
+		}
+		if node.Unique != nil {
+			node.Unique = rewriteUnique(node.Unique, recurse)
+		}
+	case *AddColumnActionNode:
+		if r := recurse(node.Column); r != nil {
+			node.Column = r.(*ColumnSchemaNode)
+		}
+	case *DropColumnActionNode:
+		node.Column = rewriteIdentifier(node.Column, recurse)
+	case *RenameColumnActionNode:
+		node.From = rewriteIdentifier(node.From, recurse)
+		node.To = rewriteIdentifier(node.To, recurse)
+	case *RenameTableActionNode:
+		node.To = rewriteIdentifier(node.To, recurse)
+	case *ModifyColumnActionNode:
+		node.Column = rewriteIdentifier(node.Column, recurse)
+		node.DataType = rewriteType(node.DataType, recurse)
+	case *AddConstraintActionNode:
+		node.Name = rewriteIdentifier(node.Name, recurse)
+		node.Constraint = recurse(node.Constraint)
+	case *DropConstraintActionNode:
+		node.Name = rewriteIdentifier(node.Name, recurse)
+	case *AlterTableStmtNode:
+		node.Table = rewriteIdentifier(node.Table, recurse)
+		for i, a := range node.Actions {
+			if r := recurse(a); r != nil {
+				node.Actions[i] = r.(AlterTableActionNode)
+			}
+		}
+	case *CreateViewStmtNode:
+		node.View = rewriteIdentifier(node.View, recurse)
+		node.Query = rewriteStmt(node.Query, recurse)
+	case *DropViewStmtNode:
+		node.View = rewriteIdentifier(node.View, recurse)
+	default:
+		// Node types with no case above -- leaf nodes (values, type nodes,
+		// zero-field option nodes), nodes whose children are plain Node
+		// fields (IdentifierNode.Qualifier, SubqueryOperatorNode.Query,
+		// ExistsOperatorNode.Query), and any node type added after this
+		// switch was last updated -- fall through to the generic
+		// reflection-based rewriter.
+		rewriteReflect(n, recurse)
+	}
+}
+
+// nodeType is the reflect.Type of the Node interface, used to recognize
+// struct fields that hold child nodes generically.
+var nodeType = reflect.TypeOf((*Node)(nil)).Elem()
+
+// rewriteReflect is the generic fallback for rewriteChildren. It walks n's
+// exported struct fields by reflection and recurses into every field whose
+// type -- or, for a slice field, whose element type -- implements Node,
+// writing back whatever recurse produces.
+//
+// Unlike Dump (see unparse.go), this does not skip fields tagged
+// `print:"-"`: that tag marks a field hidden from ast dumps, not exempt
+// from rewriting. None of today's print:"-" fields (NodeBase.Position,
+// NodeBase.Length, TaggedExprNodeBase.Type, StmtNodeBase.Verb) implement
+// Node, so this only matters for a future field that is both -- it will
+// still be visited here even though Dump won't print it. This lets a node
+// type added later pick up correct, automatic traversal without anyone
+// having to remember to add a case above -- the hand-written cases remain
+// only where a field needs special handling (a conditionally-nil operand,
+// a narrower interface to assert back to, and so on).
+func rewriteReflect(n Node, recurse func(Node) Node) {
+	v := reflect.ValueOf(n)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return
+	}
+	rewriteReflectStruct(v.Elem(), recurse)
+}
+
+func rewriteReflectStruct(v reflect.Value, recurse func(Node) Node) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue // unexported
+		}
+
+		fv := v.Field(i)
+		switch {
+		case field.Anonymous && fv.Kind() == reflect.Struct:
+			rewriteReflectStruct(fv, recurse)
+		case fv.Type().Implements(nodeType):
+			rewriteReflectField(fv, recurse)
+		case fv.Kind() == reflect.Slice && fv.Type().Elem().Implements(nodeType):
+			for j := 0; j < fv.Len(); j++ {
+				rewriteReflectField(fv.Index(j), recurse)
+			}
+		}
+	}
+}
+
+// rewriteReflectField rewrites a single struct field or slice element whose
+// static type implements Node, in place.
+func rewriteReflectField(fv reflect.Value, recurse func(Node) Node) {
+	if fv.IsNil() {
+		return
+	}
+	child := fv.Interface().(Node)
+	r := recurse(child)
+	if r == nil {
+		fv.Set(reflect.Zero(fv.Type()))
+		return
+	}
+	rv := reflect.ValueOf(r)
+	if rv.Type().AssignableTo(fv.Type()) {
+		fv.Set(rv)
+	}
+}
@@ -0,0 +1,57 @@
+package ast
+
+import "testing"
+
+// TestRewriteDeletesDirectOptionChildren guards against the rewriteChildren
+// setter table recursing into an option node's own children (via
+// rewriteChildren) instead of routing it through recurse: doing the former
+// means Rewrite/Edit's f never runs on the option node itself, so it can
+// never be deleted or replaced wholesale even though GetChildren exposes it
+// as a direct child of the statement.
+func TestRewriteDeletesDirectOptionChildren(t *testing.T) {
+	stmt := &SelectStmtNode{
+		Table: &TableNameNode{Table: &IdentifierNode{Name: []byte("t")}},
+		Where: &WhereOptionNode{Condition: &BoolValueNode{V: true}},
+		Limit: &LimitOptionNode{Value: intLit(1)},
+	}
+
+	out := Rewrite(stmt, func(n Node) Node {
+		if _, ok := n.(*WhereOptionNode); ok {
+			return nil
+		}
+		if _, ok := n.(*LimitOptionNode); ok {
+			return nil
+		}
+		return n
+	}).(*SelectStmtNode)
+
+	if out.Where != nil {
+		t.Errorf("Where = %#v, want nil", out.Where)
+	}
+	if out.Limit != nil {
+		t.Errorf("Limit = %#v, want nil", out.Limit)
+	}
+}
+
+// TestRewriteDeletesUpdateAssignment guards the same bug for a single
+// UpdateStmtNode.Assignment entry, the motivating example this request's own
+// body named.
+func TestRewriteDeletesUpdateAssignment(t *testing.T) {
+	keep := &AssignOperatorNode{Column: &IdentifierNode{Name: []byte("b")}, Expr: intLit(2)}
+	drop := &AssignOperatorNode{Column: &IdentifierNode{Name: []byte("a")}, Expr: intLit(1)}
+	stmt := &UpdateStmtNode{
+		Table:      &TableNameNode{Table: &IdentifierNode{Name: []byte("t")}},
+		Assignment: []*AssignOperatorNode{drop, keep},
+	}
+
+	out := Rewrite(stmt, func(n Node) Node {
+		if a, ok := n.(*AssignOperatorNode); ok && a == drop {
+			return nil
+		}
+		return n
+	}).(*UpdateStmtNode)
+
+	if len(out.Assignment) != 2 || out.Assignment[0] != nil || out.Assignment[1] != keep {
+		t.Errorf("Assignment = %#v, want [nil, keep]", out.Assignment)
+	}
+}